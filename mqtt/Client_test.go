@@ -0,0 +1,222 @@
+package mqtt
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestTopicMatches(t *testing.T) {
+	tests := []struct {
+		filter string
+		topic  string
+		want   bool
+	}{
+		{"table/set", "table/set", true},
+		{"table/set", "table/state", false},
+		{"table/+/set", "table/active/set", true},
+		{"table/+/set", "table/active/next/set", false},
+		{"table/#", "table/active/set", true},
+		{"table/#", "table", true}, // # includes the parent level
+		{"#", "table/set", true},
+		{"+", "table", true},
+		{"+", "table/set", false},
+		{"table/set", "table/set/more", false},
+		{"table/set/more", "table/set", false},
+	}
+	for _, test := range tests {
+		if got := TopicMatches(test.filter, test.topic); got != test.want {
+			t.Errorf("TopicMatches(%q, %q) = %v, want %v", test.filter, test.topic, got, test.want)
+		}
+	}
+}
+
+// readString reads a length prefixed string and returns it with the rest.
+func readString(t *testing.T, data []byte) (string, []byte) {
+	if len(data) < 2 || len(data) < 2+int(data[0])<<8|int(data[1]) {
+		t.Fatalf("truncated string in % x", data)
+	}
+	length := int(data[0])<<8 | int(data[1])
+	return string(data[2 : 2+length]), data[2+length:]
+}
+
+// roundTrip encodes a packet and reads it back like the client does.
+func roundTrip(t *testing.T, header byte, body []byte) (byte, []byte) {
+	packet := append(appendLength([]byte{header}, len(body)), body...)
+	readHeader, readBody, err := readPacket(bufio.NewReader(bytes.NewReader(packet)))
+	if err != nil {
+		t.Fatalf("readPacket: %v", err)
+	}
+	return readHeader, readBody
+}
+
+func TestEncodeConnect(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		password string
+		will     *Will
+		flags    byte
+		fields   []string
+	}{
+		{"plain", "", "", nil, 0x02, []string{"table"}},
+		{"auth", "user", "secret", nil, 0xc2, []string{"table", "user", "secret"}},
+		{"user only", "user", "", nil, 0x82, []string{"table", "user"}},
+		{"will", "", "", &Will{Topic: "table/availability", Payload: []byte("offline"), Retain: true}, 0x26, []string{"table", "table/availability", "offline"}},
+	}
+	for _, test := range tests {
+		header, body := encodeConnect("table", test.username, test.password, test.will)
+		header, body = roundTrip(t, header, body)
+		if header != packetConnect<<4 {
+			t.Errorf("%s: header %x", test.name, header)
+		}
+		protocol, rest := readString(t, body)
+		if protocol != "MQTT" || rest[0] != 4 {
+			t.Errorf("%s: protocol %q level %d", test.name, protocol, rest[0])
+		}
+		if rest[1] != test.flags {
+			t.Errorf("%s: flags %x, want %x", test.name, rest[1], test.flags)
+		}
+		if keepAliveSeconds := int(rest[2])<<8 | int(rest[3]); keepAliveSeconds != int(keepAlive/time.Second) {
+			t.Errorf("%s: keep alive %d", test.name, keepAliveSeconds)
+		}
+		rest = rest[4:]
+		for _, want := range test.fields {
+			var field string
+			field, rest = readString(t, rest)
+			if field != want {
+				t.Errorf("%s: field %q, want %q", test.name, field, want)
+			}
+		}
+		if len(rest) != 0 {
+			t.Errorf("%s: %d bytes left", test.name, len(rest))
+		}
+	}
+}
+
+func TestPublish(t *testing.T) {
+	tests := []struct {
+		topic   string
+		payload []byte
+		retain  bool
+	}{
+		{"table/state", []byte(`{"state":"ON"}`), true},
+		{"table/active", []byte("left"), false},
+		{"table/empty", []byte{}, false},
+		{"table/large", bytes.Repeat([]byte("x"), 300), false},
+	}
+	for _, test := range tests {
+		header, body := encodePublish(test.topic, test.payload, test.retain)
+		header, body = roundTrip(t, header, body)
+		if header>>4 != packetPublish || (header&1 == 1) != test.retain {
+			t.Errorf("%s: header %x", test.topic, header)
+		}
+		topic, payload, err := decodePublish(header, body)
+		if err != nil || topic != test.topic || !bytes.Equal(payload, test.payload) {
+			t.Errorf("%s: decoded %q %q %v", test.topic, topic, payload, err)
+		}
+	}
+}
+
+func TestDecodePublish(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  byte
+		body    []byte
+		topic   string
+		payload string
+		err     bool
+	}{
+		{"qos 0", packetPublish << 4, []byte("\x00\x01ab"), "a", "b", false},
+		{"qos 1 skips packet id", packetPublish<<4 | 2, []byte("\x00\x01a\x00\x07b"), "a", "b", false},
+		{"empty", packetPublish << 4, []byte{}, "", "", true},
+		{"short topic", packetPublish << 4, []byte("\x00\x05ab"), "", "", true},
+		{"qos 1 without packet id", packetPublish<<4 | 2, []byte("\x00\x01a"), "", "", true},
+	}
+	for _, test := range tests {
+		topic, payload, err := decodePublish(test.header, test.body)
+		if (err != nil) != test.err {
+			t.Errorf("%s: error %v", test.name, err)
+			continue
+		}
+		if !test.err && (topic != test.topic || string(payload) != test.payload) {
+			t.Errorf("%s: decoded %q %q", test.name, topic, payload)
+		}
+	}
+}
+
+func TestEncodeSubscribe(t *testing.T) {
+	tests := []struct {
+		id    uint16
+		topic string
+	}{
+		{1, "table/set"},
+		{0x1234, "table/+/set"},
+	}
+	for _, test := range tests {
+		header, body := encodeSubscribe(test.id, test.topic)
+		header, body = roundTrip(t, header, body)
+		if header != packetSubscribe<<4|2 {
+			t.Errorf("%s: header %x", test.topic, header)
+		}
+		if id := uint16(body[0])<<8 | uint16(body[1]); id != test.id {
+			t.Errorf("%s: packet id %x", test.topic, id)
+		}
+		topic, rest := readString(t, body[2:])
+		if topic != test.topic || len(rest) != 1 || rest[0] != 0 {
+			t.Errorf("%s: decoded %q % x", test.topic, topic, rest)
+		}
+	}
+}
+
+func TestReadPacketRejectsLargeLength(t *testing.T) {
+	packet := appendLength([]byte{packetPublish << 4}, maxPacketLength+1)
+	_, _, err := readPacket(bufio.NewReader(bytes.NewReader(packet)))
+	if err == nil {
+		t.Error("expected an error for a packet above the maximum length")
+	}
+}
+
+// TestBroker publishes and receives a message through a real broker, by
+// default on localhost:1883, set MQTT_BROKER to use another one.
+func TestBroker(t *testing.T) {
+	broker := os.Getenv("MQTT_BROKER")
+	if broker == "" {
+		broker = "tcp://localhost:1883"
+	}
+	client, err := NewClient(broker, "boardgametable-test", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := make(chan string, 1)
+	client.Subscribe("boardgametable-test/+/set", func(topic string, payload []byte) {
+		received <- topic + " " + string(payload)
+	})
+	err = client.Start()
+	var networkErr *net.OpError
+	if errors.As(err, &networkErr) {
+		t.Skipf("no broker reachable at %s: %v", broker, err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	// the subscription is active once the broker has processed it
+	time.Sleep(200 * time.Millisecond)
+	err = client.Publish("boardgametable-test/active/set", []byte("left"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case message := <-received:
+		if message != "boardgametable-test/active/set left" {
+			t.Errorf("received %q", message)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("no message received")
+	}
+}
@@ -1,43 +1,103 @@
-function setColor(direction, color) {
-  var arr = color.split('');
-  var leds = "0,40,";
-  if (direction==1)
-    leds = "45,115,";
-  else if (direction==2)
-    leds = "120,156,";
-  else if (direction==3)
-    leds = "165,236,";
-  var colormap = leds+arr[1]+arr[2]+","+arr[3]+arr[4]+","+arr[5]+arr[6];
-  var brightness = document.getElementById("brightness").value;
-  console.log("setting colormap " + colormap + " and brightness to " + brightness);
+// positions around the table of the seats a layout may have
+var seatPositions = ["right", "bottom", "left", "top"];
+var tables = [];
+
+function loadTables() {
   var xmlHttp = new XMLHttpRequest();
-  xmlHttp.open("GET", "/api?command=startcolormap&map=" + colormap + "&brightness=" + brightness, false);
+  xmlHttp.open("GET", "/api?command=tables", false);
   xmlHttp.send(null);
-  console.log("response: "+ xmlHttp.status);
+  if (xmlHttp.status != 200) {
+    buildSeats();
+    return;
+  }
+  tables = JSON.parse(xmlHttp.responseText);
+  var select = document.getElementById("table");
+  tables.forEach(function(table) {
+    var option = document.createElement("option");
+    option.value = table.id;
+    option.textContent = table.name;
+    select.appendChild(option);
+  });
+  // only offer a selection when the server manages several tables
+  if (tables.length > 1)
+    document.getElementById("tableSelection").style.display = "block";
+  buildSeats();
 }
 
-function updateColors() {
-  var colorLeft = document.getElementById("colorLeft").value.split('');
-  var colorRight = document.getElementById("colorRight").value.split('');
-  var colorTop = document.getElementById("colorTop").value.split('');
-  var colorBottom = document.getElementById("colorBottom").value.split('');
-  var colormapLeft = "120,156,"+colorLeft[1]+colorLeft[2]+","+colorLeft[3]+colorLeft[4]+","+colorLeft[5]+colorLeft[6];
-  var colormapRight = "0,40,"+colorRight[1]+colorRight[2]+","+colorRight[3]+colorRight[4]+","+colorRight[5]+colorRight[6];
-  var colormapTop = "165,236,"+colorTop[1]+colorTop[2]+","+colorTop[3]+colorTop[4]+","+colorTop[5]+colorTop[6];
-  var colormapBottom = "45,115,"+colorBottom[1]+colorBottom[2]+","+colorBottom[3]+colorBottom[4]+","+colorBottom[5]+colorBottom[6];
-  var colormap = colormapLeft+"-"+colormapRight+"-"+colormapTop+"-"+colormapBottom;
+// currentSeats returns the seats of the selected table in clockwise order
+function currentSeats() {
+  var select = document.getElementById("table");
+  for (var i = 0; i < tables.length; i++) {
+    if (tables[i].id == select.value)
+      return tables[i].seats || [];
+  }
+  if (tables.length > 0)
+    return tables[0].seats || [];
+  return seatPositions;
+}
+
+// buildSeats creates the color picker, active button and brightness slider
+// for each seat of the selected table
+function buildSeats() {
+  var seats = currentSeats();
+  seatPositions.forEach(function(seat) {
+    var cell = document.getElementById("seat-" + seat);
+    cell.innerHTML = "";
+    if (seats.indexOf(seat) < 0)
+      return;
+    var color = document.createElement("input");
+    color.type = "color";
+    color.id = "color-" + seat;
+    color.onchange = function() { setColor(seat, this.value); };
+    var active = document.createElement("button");
+    active.textContent = "ACTIVE";
+    active.onclick = function() { setActive(seat); };
+    var brightness = document.createElement("input");
+    brightness.type = "range";
+    brightness.min = "0";
+    brightness.max = "100";
+    brightness.value = "100";
+    brightness.className = "seatslider";
+    brightness.onchange = function() { setSeatBrightness(seat, this.value); };
+    cell.appendChild(color);
+    cell.appendChild(document.createElement("br"));
+    cell.appendChild(active);
+    cell.appendChild(document.createElement("br"));
+    cell.appendChild(brightness);
+  });
+}
+
+function tableParam() {
+  var select = document.getElementById("table");
+  if (select == null || select.value == "")
+    return "";
+  return "&table=" + encodeURIComponent(select.value);
+}
+
+function sendColormap(colormap) {
   var brightness = document.getElementById("brightness").value;
-  console.log("setting all colors: " + colormap + " and brightness to " + brightness);
+  console.log("setting colormap " + colormap + " and brightness to " + brightness);
   var xmlHttp = new XMLHttpRequest();
-  xmlHttp.open("GET", "/api?command=startcolormap&map=" + colormap + "&brightness=" + brightness, false);
+  xmlHttp.open("GET", "/api?command=startcolormap&map=" + encodeURIComponent(colormap) + "&brightness=" + brightness + tableParam(), false);
   xmlHttp.send(null);
   console.log("response: "+ xmlHttp.status);
 }
 
+function setColor(seat, color) {
+  sendColormap(seat + "=" + color);
+}
+
+function updateColors() {
+  var colormap = currentSeats().map(function(seat) {
+    return seat + "=" + document.getElementById("color-" + seat).value;
+  });
+  sendColormap(colormap.join(";"));
+}
+
 function setBrightness(brightness) {
   console.log("setting brightness to " + brightness);
   var xmlHttp = new XMLHttpRequest();
-  xmlHttp.open("GET", "/api?command=brightness&value="+ brightness, false);
+  xmlHttp.open("GET", "/api?command=brightness&value="+ brightness + tableParam(), false);
   xmlHttp.send(null);
   console.log("Response: "+ xmlHttp.status);
 }
@@ -45,15 +105,73 @@ function setBrightness(brightness) {
 function setActive(direction) {
   console.log("setting direction active: " + direction);
   var xmlHttp = new XMLHttpRequest();
-  xmlHttp.open("GET", "/api?command=active&direction="+ direction, false);
+  xmlHttp.open("GET", "/api?command=active&direction="+ direction + tableParam(), false);
   xmlHttp.send(null);
   console.log("Response: "+ xmlHttp.status);
 }
 
+function setSeatBrightness(direction, brightness) {
+  console.log("setting brightness of " + direction + " to " + brightness);
+  var xmlHttp = new XMLHttpRequest();
+  xmlHttp.open("GET", "/api?command=seatbrightness&direction=" + direction + "&value=" + brightness + tableParam(), false);
+  xmlHttp.send(null);
+  console.log("Response: "+ xmlHttp.status);
+}
+
+function setHighlight() {
+  var style = document.getElementById("highlightStyle").value;
+  var speed = document.getElementById("highlightSpeed").value / 10;
+  var intensity = document.getElementById("highlightIntensity").value / 100;
+  console.log("setting highlight " + style + " with speed " + speed + " and intensity " + intensity);
+  var xmlHttp = new XMLHttpRequest();
+  xmlHttp.open("GET", "/api?command=highlight&style=" + style + "&speed=" + speed + "&intensity=" + intensity + tableParam(), false);
+  xmlHttp.send(null);
+  console.log("Response: "+ xmlHttp.status);
+}
+
+function nextPhase() {
+  console.log("advancing phase");
+  var xmlHttp = new XMLHttpRequest();
+  xmlHttp.open("GET", "/api?command=nextphase" + tableParam(), false);
+  xmlHttp.send(null);
+  console.log("Response: "+ xmlHttp.status);
+  if (xmlHttp.status == 200) {
+    var result = JSON.parse(xmlHttp.responseText);
+    document.getElementById("phase").textContent = "ROUND " + result.round + ": " + result.phase;
+  }
+}
+
+function pickFirstPlayer() {
+  console.log("picking first player");
+  var xmlHttp = new XMLHttpRequest();
+  xmlHttp.open("GET", "/api?command=pickfirst" + tableParam(), false);
+  xmlHttp.send(null);
+  console.log("Response: "+ xmlHttp.status);
+  if (xmlHttp.status == 200) {
+    var result = JSON.parse(xmlHttp.responseText);
+    document.getElementById("firstPlayer").textContent = "";
+    announceFirstPlayer(result.seat);
+  }
+}
+
+// announceFirstPlayer waits until the spin has stopped and the seat is active
+function announceFirstPlayer(seat) {
+  var xmlHttp = new XMLHttpRequest();
+  xmlHttp.open("GET", "/api?command=getactive" + tableParam(), false);
+  xmlHttp.send(null);
+  if (xmlHttp.status == 200 && JSON.parse(xmlHttp.responseText).indexOf(seat) >= 0) {
+    document.getElementById("firstPlayer").textContent = seat.toUpperCase() + " STARTS";
+    return;
+  }
+  setTimeout(function() {
+    announceFirstPlayer(seat);
+  }, 500);
+}
+
 function disableActive() {
   console.log("disabling direction active");
   var xmlHttp = new XMLHttpRequest();
-  xmlHttp.open("GET", "/api?command=activeoff", false);
+  xmlHttp.open("GET", "/api?command=activeoff" + tableParam(), false);
   xmlHttp.send(null);
   console.log("Response: "+ xmlHttp.status);
 }
@@ -61,7 +179,7 @@ function disableActive() {
 function reconnect() {
   console.log("reconnect controller");
   var xmlHttp = new XMLHttpRequest();
-  xmlHttp.open("GET", "/api?command=reconnect", false);
+  xmlHttp.open("GET", "/api?command=reconnect" + tableParam(), false);
   xmlHttp.send(null);
   console.log("Response: "+ xmlHttp.status);
 }
\ No newline at end of file
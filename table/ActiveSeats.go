@@ -0,0 +1,228 @@
+package table
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Team is a named group of seats that take their turn together.
+type Team struct {
+	Name  string
+	Seats []Direction
+}
+
+// GetActiveDirections returns all highlighted directions in clockwise order.
+func (pt *AnimationPlayTable) GetActiveDirections() []Direction {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	return pt.activeDirectionList()
+}
+
+func (pt *AnimationPlayTable) activeDirectionList() []Direction {
+	active := []Direction{}
+	for _, direction := range pt.layout.SeatDirections() {
+		if pt.activeDirections[direction] {
+			active = append(active, direction)
+		}
+	}
+	return active
+}
+
+// isActive returns true if the direction is highlighted.
+func (pt *AnimationPlayTable) isActive(direction Direction) bool {
+	return pt.activeDirections[direction]
+}
+
+// SetActiveDirections highlights several directions at once. The first one
+// is the direction the turn rotation continues from.
+func (pt *AnimationPlayTable) SetActiveDirections(directions []Direction) error {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	return pt.setActiveDirections(directions)
+}
+
+func (pt *AnimationPlayTable) setActiveDirections(directions []Direction) error {
+	if len(directions) == 0 {
+		return errors.New("no directions given")
+	}
+	checked := map[Direction]bool{}
+	for _, direction := range directions {
+		direction, err := pt.checkDirection(direction)
+		if err != nil {
+			return err
+		}
+		checked[direction] = true
+	}
+	primary := directions[0]
+	pt.activeDirection = &primary
+	pt.activeDirections = checked
+	pt.simultaneous = false
+	return nil
+}
+
+// AddActiveDirection highlights an additional direction.
+func (pt *AnimationPlayTable) AddActiveDirection(direction Direction) error {
+	direction, err := pt.checkDirection(direction)
+	if err != nil {
+		return err
+	}
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	if pt.activeDirection == nil {
+		pt.activeDirection = &direction
+	}
+	pt.activeDirections[direction] = true
+	return nil
+}
+
+// RemoveActiveDirection stops highlighting a direction.
+func (pt *AnimationPlayTable) RemoveActiveDirection(direction Direction) error {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	return pt.removeActiveDirection(direction)
+}
+
+func (pt *AnimationPlayTable) removeActiveDirection(direction Direction) error {
+	direction, err := pt.checkDirection(direction)
+	if err != nil {
+		return err
+	}
+	if !pt.activeDirections[direction] {
+		return errors.New("direction is not active")
+	}
+	delete(pt.activeDirections, direction)
+	if pt.activeDirection == nil || *pt.activeDirection == direction {
+		pt.activeDirection = nil
+		if remaining := pt.activeDirectionList(); len(remaining) > 0 {
+			pt.activeDirection = &remaining[0]
+		}
+	}
+	return pt.updateFrame()
+}
+
+// StartSimultaneousPhase highlights all seats in the rotation until every
+// player has marked themselves done, then the previous turn continues.
+func (pt *AnimationPlayTable) StartSimultaneousPhase() error {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	seats := []Direction{}
+	for _, direction := range pt.layout.SeatDirections() {
+		if _, ok := (*pt.playerDirections)[direction]; ok && pt.seatState(direction) == SeatPlaying {
+			seats = append(seats, direction)
+		}
+	}
+	if len(seats) == 0 {
+		return errors.New("no seat left in rotation")
+	}
+	resume := pt.activeDirection
+	err := pt.setActiveDirections(seats)
+	if err != nil {
+		return err
+	}
+	pt.simultaneous = true
+	pt.simultaneousResume = resume
+	return nil
+}
+
+// MarkDone marks a seat as done with the simultaneous phase. Returns true if
+// this was the last seat and the phase has ended.
+func (pt *AnimationPlayTable) MarkDone(direction Direction) (bool, error) {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	if !pt.simultaneous {
+		return false, errors.New("no simultaneous phase running")
+	}
+	err := pt.removeActiveDirection(direction)
+	if err != nil {
+		return false, err
+	}
+	if len(pt.activeDirections) > 0 {
+		return false, nil
+	}
+	pt.simultaneous = false
+	resume := pt.simultaneousResume
+	pt.simultaneousResume = nil
+	if resume != nil {
+		return true, pt.setActiveDirection(*resume)
+	}
+	return true, nil
+}
+
+// IsSimultaneousPhase returns true while a simultaneous phase is running.
+func (pt *AnimationPlayTable) IsSimultaneousPhase() bool {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	return pt.simultaneous
+}
+
+// SetTeam defines a team, replacing a team of the same name.
+func (pt *AnimationPlayTable) SetTeam(name string, seats []Direction) error {
+	if name == "" {
+		return errors.New("team needs a name")
+	}
+	for _, seat := range seats {
+		if _, err := pt.checkDirection(seat); err != nil {
+			return err
+		}
+	}
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	for i, team := range pt.teams {
+		if team.Name == name {
+			if len(seats) == 0 {
+				pt.teams = append(pt.teams[:i], pt.teams[i+1:]...)
+				// the rotation continues with the team after the removed one
+				if i <= pt.activeTeam {
+					pt.activeTeam--
+				}
+			} else {
+				pt.teams[i].Seats = seats
+			}
+			return nil
+		}
+	}
+	if len(seats) == 0 {
+		return fmt.Errorf("unknown team %q", name)
+	}
+	pt.teams = append(pt.teams, Team{name, seats})
+	return nil
+}
+
+// GetTeams returns the defined teams.
+func (pt *AnimationPlayTable) GetTeams() []Team {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	return append([]Team{}, pt.teams...)
+}
+
+// SetActiveTeam highlights all seats of a team.
+func (pt *AnimationPlayTable) SetActiveTeam(name string) error {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	return pt.setActiveTeam(name)
+}
+
+func (pt *AnimationPlayTable) setActiveTeam(name string) error {
+	for i, team := range pt.teams {
+		if team.Name == name {
+			err := pt.setActiveDirections(team.Seats)
+			if err != nil {
+				return err
+			}
+			pt.activeTeam = i
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown team %q", name)
+}
+
+// ActiveTeamNext highlights the next team and returns its name.
+func (pt *AnimationPlayTable) ActiveTeamNext() (string, error) {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	if len(pt.teams) == 0 {
+		return "", errors.New("no teams defined")
+	}
+	next := pt.teams[(pt.activeTeam+1)%len(pt.teams)].Name
+	return next, pt.setActiveTeam(next)
+}
@@ -0,0 +1,111 @@
+package table
+
+import (
+	"testing"
+)
+
+func TestRemoveActiveDirection(t *testing.T) {
+	_, _, playTable := newTestTable(t)
+	err := playTable.SetActiveDirections([]Direction{Directions["left"], Directions["right"]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = playTable.RemoveActiveDirection(Directions["left"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seat := activeSeat(playTable); seat != "right" {
+		t.Errorf("active seat %q after removing left, want right", seat)
+	}
+	if err = playTable.RemoveActiveDirection(Directions["left"]); err == nil {
+		t.Error("expected an error removing an inactive seat")
+	}
+	// highlighted seats without a primary one
+	err = playTable.AddActiveDirection(Directions["top"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	playTable.lock.Lock()
+	playTable.activeDirection = nil
+	playTable.lock.Unlock()
+	err = playTable.RemoveActiveDirection(Directions["right"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seat := activeSeat(playTable); seat != "top" {
+		t.Errorf("active seat %q after removing right, want top", seat)
+	}
+	err = playTable.RemoveActiveDirection(Directions["top"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seat := activeSeat(playTable); seat != "" {
+		t.Errorf("active seat %q after removing all seats", seat)
+	}
+}
+
+func TestTeamRemoval(t *testing.T) {
+	tests := []struct {
+		name    string
+		active  string
+		removed string
+		next    string
+	}{
+		{"active team removed", "blue", "blue", "green"},
+		{"last team removed while active", "green", "green", "red"},
+		{"team before the active one removed", "blue", "red", "green"},
+		{"team after the active one removed", "red", "green", "blue"},
+	}
+	for _, test := range tests {
+		_, _, playTable := newTestTable(t)
+		teams := []Team{
+			{"red", []Direction{Directions["right"]}},
+			{"blue", []Direction{Directions["bottom"], Directions["top"]}},
+			{"green", []Direction{Directions["left"]}},
+		}
+		for _, team := range teams {
+			err := playTable.SetTeam(team.Name, team.Seats)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		err := playTable.SetActiveTeam(test.active)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = playTable.SetTeam(test.removed, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		next, err := playTable.ActiveTeamNext()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next != test.next {
+			t.Errorf("%s: next team %s, want %s", test.name, next, test.next)
+		}
+	}
+	_, _, playTable := newTestTable(t)
+	err := playTable.SetTeam("red", []Direction{Directions["right"]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = playTable.SetActiveTeam("red")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = playTable.SetTeam("red", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = playTable.ActiveTeamNext(); err == nil {
+		t.Error("expected an error without teams")
+	}
+	err = playTable.SetTeam("blue", []Direction{Directions["left"]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next, err := playTable.ActiveTeamNext(); err != nil || next != "blue" {
+		t.Errorf("next team %s, error %v, want blue", next, err)
+	}
+}
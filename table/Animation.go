@@ -5,4 +5,19 @@ type Animation interface {
 	Step()
 	SetFrameBuffer(frameBuffer *[]byte)
 	GetFrameBuffer() *[]byte
-}
\ No newline at end of file
+}
+
+// FrameLocker is implemented by animations that also paint their frame
+// buffer outside of Step, e.g. when colors are changed. The frame is only
+// read while it is locked.
+type FrameLocker interface {
+	LockFrame()
+	UnlockFrame()
+}
+
+// RangePainter is implemented by animations that can paint arbitrary ranges.
+type RangePainter interface {
+	SetRangeFill(direction Direction, fill Fill) error
+	SetPixelColor(index int, color Color) error
+	ClearRanges()
+}
@@ -0,0 +1,83 @@
+package table
+
+import (
+	"fmt"
+	"math"
+)
+
+// AmbientMode selects the ambient animation.
+type AmbientMode string
+
+// Available ambient modes.
+const (
+	AmbientBreathing  AmbientMode = "breathing"
+	AmbientWarmWhite  AmbientMode = "warmwhite"
+	AmbientColorCycle AmbientMode = "colorcycle"
+	AmbientOff        AmbientMode = "off"
+)
+
+const ambientWarmWhiteKelvin = 2700
+const breathingDegreesPerStep = 0.5
+const colorCycleDegreesPerStep = 0.2
+
+// AnimationAmbient is a calm animation for when no game is running.
+type AnimationAmbient struct {
+	frameBuffer *[]byte
+	mode        AmbientMode
+	color       Color
+	phase       float64
+}
+
+// ParseAmbientMode parses an ambient mode name.
+func ParseAmbientMode(encoded string) (AmbientMode, error) {
+	switch mode := AmbientMode(encoded); mode {
+	case AmbientBreathing, AmbientWarmWhite, AmbientColorCycle, AmbientOff:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown ambient mode %q", encoded)
+}
+
+// NewAnimationAmbient creates a new AnimationAmbient.
+func NewAnimationAmbient(frameBuffer *[]byte, mode AmbientMode) *AnimationAmbient {
+	newAnimation := new(AnimationAmbient)
+	newAnimation.frameBuffer = frameBuffer
+	newAnimation.mode = mode
+	newAnimation.color, _ = KelvinToColor(ambientWarmWhiteKelvin)
+	return newAnimation
+}
+
+// SetFrameBuffer sets the frame buffer.
+func (aa *AnimationAmbient) SetFrameBuffer(frameBuffer *[]byte) {
+	aa.frameBuffer = frameBuffer
+}
+
+// GetFrameBuffer gets the frame buffer.
+func (aa *AnimationAmbient) GetFrameBuffer() *[]byte {
+	return aa.frameBuffer
+}
+
+// GetMode returns the ambient mode.
+func (aa *AnimationAmbient) GetMode() AmbientMode {
+	return aa.mode
+}
+
+// Step animates one increment.
+func (aa *AnimationAmbient) Step() {
+	if aa.frameBuffer == nil {
+		return
+	}
+	pixels := Direction{0, len(*aa.frameBuffer) / 3}
+	switch aa.mode {
+	case AmbientBreathing:
+		aa.phase += breathingDegreesPerStep
+		SolidFill(aa.color).paint(*aa.frameBuffer, pixels)
+		dimRange(*aa.frameBuffer, pixels, 0.3+0.7*(0.5+0.5*math.Sin(aa.phase*math.Pi/180)))
+	case AmbientWarmWhite:
+		SolidFill(aa.color).paint(*aa.frameBuffer, pixels)
+	case AmbientColorCycle:
+		aa.phase += colorCycleDegreesPerStep
+		SolidFill(hsvToColor(math.Mod(aa.phase, 360), 1, 1)).paint(*aa.frameBuffer, pixels)
+	default:
+		SolidFill(Color{}).paint(*aa.frameBuffer, pixels)
+	}
+}
@@ -0,0 +1,63 @@
+package table
+
+import (
+	"testing"
+)
+
+func TestParseAmbientMode(t *testing.T) {
+	tests := []struct {
+		encoded string
+		valid   bool
+	}{
+		{"breathing", true},
+		{"warmwhite", true},
+		{"colorcycle", true},
+		{"off", true},
+		{"WarmWhite", false},
+		{"disco", false},
+	}
+	for _, test := range tests {
+		mode, err := ParseAmbientMode(test.encoded)
+		if (err == nil) != test.valid {
+			t.Errorf("%q: error %v, want valid %v", test.encoded, err, test.valid)
+			continue
+		}
+		if test.valid && string(mode) != test.encoded {
+			t.Errorf("%q: mode %q", test.encoded, mode)
+		}
+	}
+}
+
+func TestAnimationAmbientStep(t *testing.T) {
+	warmWhite, err := KelvinToColor(ambientWarmWhiteKelvin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		mode  AmbientMode
+		check func(Color) bool
+	}{
+		{AmbientWarmWhite, func(pixel Color) bool { return pixel == warmWhite }},
+		// breathing never goes below 30% of the warm white
+		{AmbientBreathing, func(pixel Color) bool {
+			return pixel.r < warmWhite.r && float64(pixel.r) >= 0.3*float64(warmWhite.r)
+		}},
+		// the color cycle starts at red
+		{AmbientColorCycle, func(pixel Color) bool { return pixel.r == 255 && pixel.b == 0 }},
+		{AmbientOff, func(pixel Color) bool { return pixel == Color{} }},
+	}
+	for _, test := range tests {
+		frameBuffer := make([]byte, 10*3)
+		for i := range frameBuffer {
+			frameBuffer[i] = 255
+		}
+		animation := NewAnimationAmbient(&frameBuffer, test.mode)
+		animation.Step()
+		for i := 0; i < 10; i++ {
+			if pixel := pixelAt(&frameBuffer, i); !test.check(pixel) {
+				t.Errorf("%s: pixel %d is %s", test.mode, i, pixel.Hex())
+				break
+			}
+		}
+	}
+}
@@ -2,14 +2,16 @@ package table
 
 import (
 	"math"
-	"strings"
 	"fmt"
 	"errors"
+	"sync"
 )
 
 const maxFadeDegrees = 160
 const startFadeDegrees = 20
 const incrementFadeDegrees = 3
+const defaultFocusLevel = 0.3
+const defaultFocusFadeSteps = 50
 
 // Color describes a color.
 type Color struct {
@@ -21,13 +23,37 @@ type Direction struct {
 	start, end int
 }
 
-// AnimationPlayTable describes a table setup.
+// AnimationPlayTable describes a table setup. It is changed by requests
+// while the animation loop steps it, lock guards all of its state.
 type AnimationPlayTable struct {
+	lock sync.Mutex
 	frameBuffer *[]byte
-	playerDirections *map[Direction]Color
+	layout Layout
+	playerDirections *map[Direction]Fill
+	ranges []ColormapEntry
+	seatBrightness map[Direction]float64
 	activeDirection *Direction
-	currentFadeStep byte
-	maxFadeSteps byte
+	activeDirections map[Direction]bool
+	simultaneous bool
+	simultaneousResume *Direction
+	teams []Team
+	activeTeam int
+	highlight Highlight
+	highlightPhase float64
+	focusEnabled bool
+	focusLevel float64
+	focusFadeSteps int
+	focusLevels map[Direction]float64
+	seatStates map[Direction]SeatState
+	reversed bool
+	phases []Phase
+	currentPhase int
+	round int
+	phaseStep int
+	flourishStep int
+	scoreTarget int
+	scores map[Direction]int
+	scoreLeader *Direction
 }
 
 // Colors is a set of predefined colors.
@@ -50,71 +76,218 @@ var Directions = map[string]Direction {
 	"top": Direction{165, 236},
 }
 
-// NewAnimationPlayTable creates a new AnimationPlayTable from an encoded colormap.
-func NewAnimationPlayTable(frameBuffer *[]byte) (*AnimationPlayTable) {
+// NewAnimationPlayTable creates a new AnimationPlayTable for the seats of a layout.
+func NewAnimationPlayTable(frameBuffer *[]byte, layout Layout) (*AnimationPlayTable) {
 	newAnimation := new(AnimationPlayTable)
 	newAnimation.frameBuffer = frameBuffer
-	newAnimation.playerDirections = &map[Direction]Color{}
-	newAnimation.currentFadeStep = startFadeDegrees
+	newAnimation.layout = layout
+	newAnimation.playerDirections = &map[Direction]Fill{}
+	newAnimation.seatBrightness = map[Direction]float64{}
+	newAnimation.highlight = DefaultHighlight
+	newAnimation.focusLevel = defaultFocusLevel
+	newAnimation.focusFadeSteps = defaultFocusFadeSteps
+	newAnimation.focusLevels = map[Direction]float64{}
+	newAnimation.seatStates = map[Direction]SeatState{}
+	newAnimation.scores = map[Direction]int{}
 	newAnimation.activeDirection = nil
+	newAnimation.activeDirections = map[Direction]bool{}
+	newAnimation.activeTeam = -1
 	return newAnimation
 }
 
-// SetFrameBuffer sets the frame buffer.
+// AdoptSettings takes over the session settings of a previous animation,
+// like the seat brightness, so they survive a change of colors.
+func (pt *AnimationPlayTable) AdoptSettings(previous *AnimationPlayTable) {
+	if previous == nil || previous == pt {
+		return
+	}
+	previous.lock.Lock()
+	defer previous.lock.Unlock()
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	for direction, brightness := range previous.seatBrightness {
+		pt.seatBrightness[direction] = brightness
+	}
+	pt.highlight = previous.highlight
+	pt.focusEnabled = previous.focusEnabled
+	pt.focusLevel = previous.focusLevel
+	pt.focusFadeSteps = previous.focusFadeSteps
+	for direction, state := range previous.seatStates {
+		pt.seatStates[direction] = state
+	}
+	pt.reversed = previous.reversed
+	pt.teams = append([]Team{}, previous.teams...)
+	pt.phases = previous.phases
+	pt.currentPhase = previous.currentPhase
+	pt.round = previous.round
+	pt.scoreTarget = previous.scoreTarget
+	for direction, score := range previous.scores {
+		pt.scores[direction] = score
+	}
+	pt.scoreLeader = previous.scoreLeader
+	pt.updateFrame()
+}
+
+// SetFrameBuffer sets the frame buffer and repaints it.
 func (pt *AnimationPlayTable) SetFrameBuffer(frameBuffer *[]byte) {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
 	pt.frameBuffer = frameBuffer
+	pt.updateFrame()
 }
 
 // GetFrameBuffer gets the frame buffer.
 func (pt *AnimationPlayTable) GetFrameBuffer() *[]byte {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
 	return pt.frameBuffer
 }
 
+// LockFrame locks the frame buffer against changes.
+func (pt *AnimationPlayTable) LockFrame() {
+	pt.lock.Lock()
+}
+
+// UnlockFrame unlocks the frame buffer.
+func (pt *AnimationPlayTable) UnlockFrame() {
+	pt.lock.Unlock()
+}
+
+// GetLayout returns the layout with the seats of the table, it does not
+// change after the table is created.
+func (pt *AnimationPlayTable) GetLayout() Layout {
+	return pt.layout
+}
+
 // Step animates one increment.
 func (pt *AnimationPlayTable) Step() {
-	// if active player is set, overwrite the player's color with the gradient
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	fading := pt.stepFocus()
+	pt.phaseStep++
+	// if active player is set, overwrite the frame with the highlight
 	if pt.activeDirection != nil {
 		// first, update buffer with original color
-		pt.updateFrame()		
-		if pt.currentFadeStep>=maxFadeDegrees {
-			pt.currentFadeStep = startFadeDegrees
+		pt.updateFrame()
+		pt.highlightPhase += pt.highlight.Speed
+		seats := []Direction{}
+		for direction := range *pt.playerDirections {
+			seats = append(seats, direction)
+		}
+		pt.highlight.apply(*pt.frameBuffer, pt.activeDirectionList(), seats, pt.highlightPhase)
+	} else if fading || len(pt.phases) > 0 || pt.flourishStep > 0 {
+		pt.updateFrame()
+	}
+	if pt.flourishStep > 0 {
+		pt.paintFlourish()
+		pt.flourishStep--
+	}
+}
+
+// SetFocus enables or disables the focus mode. In focus mode all seats but
+// the active one fade to level within fadeSteps animation steps.
+func (pt *AnimationPlayTable) SetFocus(enabled bool, level float64, fadeSteps int) error {
+	if level < 0 || level > 1 {
+		return errors.New("focus level out of range")
+	}
+	if fadeSteps < 1 {
+		return errors.New("focus fade steps must be positive")
+	}
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	pt.focusEnabled = enabled
+	pt.focusLevel = level
+	pt.focusFadeSteps = fadeSteps
+	return nil
+}
+
+// GetFocus returns the focus mode settings.
+func (pt *AnimationPlayTable) GetFocus() (bool, float64, int) {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	return pt.focusEnabled, pt.focusLevel, pt.focusFadeSteps
+}
+
+// stepFocus moves the seat levels one step towards their focus target and
+// returns true if any level has changed.
+func (pt *AnimationPlayTable) stepFocus() bool {
+	changed := false
+	delta := 1 / float64(pt.focusFadeSteps)
+	for direction := range *pt.playerDirections {
+		target := 1.0
+		if pt.focusEnabled && pt.activeDirection != nil && !pt.isActive(direction) {
+			target = pt.focusLevel
+		}
+		current, ok := pt.focusLevels[direction]
+		if !ok {
+			current = 1
+		}
+		if current == target {
+			continue
+		}
+		if current < target {
+			current = math.Min(target, current+delta)
 		} else {
-			pt.currentFadeStep+=incrementFadeDegrees
+			current = math.Max(target, current-delta)
 		}
-		currentFade := math.Sin(float64(pt.currentFadeStep)*math.Pi/180)
-		for i:=(*pt.activeDirection).start*3; i<(*pt.activeDirection).end*3; i+=3 {
-			if i<len(*pt.frameBuffer)-3 {
-				fadedColorR := float64((*pt.frameBuffer)[i]) * currentFade
-				fadedColorG := float64((*pt.frameBuffer)[i+1]) * currentFade
-				fadedColorB := float64((*pt.frameBuffer)[i+2]) * currentFade
-				(*pt.frameBuffer)[i] = byte(fadedColorR);
-				(*pt.frameBuffer)[i+1] = byte(fadedColorG);
-				(*pt.frameBuffer)[i+2] = byte(fadedColorB);
-			} 
+		if current == 1 {
+			delete(pt.focusLevels, direction)
+		} else {
+			pt.focusLevels[direction] = current
 		}
+		changed = true
 	}
+	return changed
+}
+
+// SetHighlight sets the highlight style of the active seat.
+func (pt *AnimationPlayTable) SetHighlight(highlight Highlight) {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	pt.highlight = highlight
+	pt.highlightPhase = 0
+}
+
+// GetHighlight returns the highlight style of the active seat.
+func (pt *AnimationPlayTable) GetHighlight() Highlight {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	return pt.highlight
 }
 
 func (pt *AnimationPlayTable) updateFrame() error {
 	if pt.frameBuffer == nil {
 		return errors.New("no frame buffer declared")
 	}
-	for direction, color := range *pt.playerDirections {
-		for i:=direction.start*3; i<direction.end*3; i+=3 {
-			if i<len(*pt.frameBuffer)-3 {
-				(*pt.frameBuffer)[i] = color.r;
-				(*pt.frameBuffer)[i+1] = color.g;
-				(*pt.frameBuffer)[i+2] = color.b;	
-			} else {
-				return errors.New("frame pixel out of bounds")
-			}
+	for direction, fill := range *pt.playerDirections {
+		err := fill.paint(*pt.frameBuffer, direction)
+		if err != nil {
+			return err
+		}
+		pt.paintScore(direction)
+		if brightness, ok := pt.seatBrightness[direction]; ok {
+			dimRange(*pt.frameBuffer, direction, brightness)
+		}
+		if level, ok := pt.focusLevels[direction]; ok {
+			dimRange(*pt.frameBuffer, direction, level)
+		}
+		if state, ok := pt.seatStates[direction]; ok {
+			dimRange(*pt.frameBuffer, direction, state.seatLevel())
+		}
+	}
+	// ranges are painted in order on top of the seats
+	for _, entry := range pt.ranges {
+		err := entry.Fill.paint(*pt.frameBuffer, entry.Direction())
+		if err != nil {
+			return err
 		}
 	}
+	pt.paintPhase()
 	return nil
 }
 
 func (pt *AnimationPlayTable) checkDirection(input Direction) (Direction, error) {
-	for _, direction := range Directions {
+	for _, direction := range pt.layout.Seats {
 		if direction.start == input.start && direction.end == input.end {
 			return direction, nil
 		}
@@ -124,75 +297,185 @@ func (pt *AnimationPlayTable) checkDirection(input Direction) (Direction, error)
 
 // SetPlayerColor sets the color of a direction.
 func (pt *AnimationPlayTable) SetPlayerColor(direction Direction, color Color) error {
+	return pt.SetPlayerFill(direction, SolidFill(color))
+}
+
+// SetPlayerFill sets the fill of a direction.
+func (pt *AnimationPlayTable) SetPlayerFill(direction Direction, fill Fill) error {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	return pt.setPlayerFill(direction, fill)
+}
+
+func (pt *AnimationPlayTable) setPlayerFill(direction Direction, fill Fill) error {
 	direction, err := pt.checkDirection(direction)
 	if err != nil {
 		return err
 	}
-	(*pt.playerDirections)[direction] = color
+	(*pt.playerDirections)[direction] = fill
 	// update the frame buffer
 	pt.updateFrame()
 	return nil
 }
 
-// SetPlayerColorFromString parses and sets the colors from a string-encoded mapping. 
-// The mapping	needs to have the following format: s,e,r,g,b[-s,e,r,g,b]*.
+// SetPlayerColorFromString parses and sets the colors from a string-encoded colormap.
+// See Colormap for the format.
 func (pt *AnimationPlayTable) SetPlayerColorFromString(encoded string) error {
-	var start int
-	var end int
-	var colorR, colorG, colorB byte
-	mapSlice := strings.Split(encoded, "-")
-	for _, encodedEntry := range mapSlice {
-		fmt.Sscanf(encodedEntry, "%d,%d,%x,%x,%x", &start, &end, &colorR, &colorG, &colorB)
-		direction, err := pt.checkDirection(Direction{start, end})
-		if err != nil {
-			return err
+	colormap, err := pt.layout.ParseColormap(encoded)
+	if err != nil {
+		return err
+	}
+	return pt.ApplyColormap(colormap)
+}
+
+// ApplyColormap sets seat colors and range fills from a colormap.
+func (pt *AnimationPlayTable) ApplyColormap(colormap Colormap) error {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	// check all entries before anything is changed
+	ranges := pt.ranges
+	for i, entry := range colormap {
+		if pt.frameBuffer != nil && entry.End*3 > len(*pt.frameBuffer) {
+			return fmt.Errorf("colormap entry %d (%q): range exceeds the strip", i+1, entry.String())
 		}
-		err = pt.SetPlayerColor(direction, Color{colorR, colorG, colorB})	
-		if err != nil {
-			return err
+		if entry.Seat == "" {
+			var err error
+			ranges, err = addRange(pt.frameBuffer, ranges, entry.Direction(), entry.Fill)
+			if err != nil {
+				return fmt.Errorf("colormap entry %d (%q): %v", i+1, entry.String(), err)
+			}
 		}
 	}
+	for _, entry := range colormap {
+		if entry.Seat != "" {
+			(*pt.playerDirections)[entry.Direction()] = entry.Fill
+		}
+	}
+	pt.ranges = ranges
+	pt.updateFrame()
 	return nil
 }
 
+// SetRangeFill paints a range of pixels. Ranges matching a seat set the seat's fill.
+func (pt *AnimationPlayTable) SetRangeFill(direction Direction, fill Fill) error {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	if _, err := pt.checkDirection(direction); err == nil {
+		return pt.setPlayerFill(direction, fill)
+	}
+	ranges, err := addRange(pt.frameBuffer, pt.ranges, direction, fill)
+	if err != nil {
+		return err
+	}
+	pt.ranges = ranges
+	return pt.updateFrame()
+}
+
+// SetPixelColor sets the color of a single pixel.
+func (pt *AnimationPlayTable) SetPixelColor(index int, color Color) error {
+	return pt.SetRangeFill(Direction{index, index + 1}, SolidFill(color))
+}
+
+// ClearRanges removes all ranges that are not tied to a seat.
+func (pt *AnimationPlayTable) ClearRanges() {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	if pt.frameBuffer != nil {
+		for _, entry := range pt.ranges {
+			SolidFill(Color{}).paint(*pt.frameBuffer, entry.Direction())
+		}
+	}
+	pt.ranges = []ColormapEntry{}
+	pt.updateFrame()
+}
+
+// GetColormap exports a copy of the current seat colors and range fills as
+// a colormap.
+func (pt *AnimationPlayTable) GetColormap() Colormap {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	colormap := Colormap{}
+	for _, name := range SeatNames {
+		direction, seat := pt.layout.Seat(name)
+		if !seat {
+			continue
+		}
+		if fill, ok := (*pt.playerDirections)[direction]; ok {
+			colormap = append(colormap, ColormapEntry{name, direction.start, direction.end, fill})
+		}
+	}
+	return append(colormap, pt.ranges...)
+}
+
+// SetSeatBrightness sets the brightness of a seat from 0 (off) to 1 (full),
+// independent of the controller brightness.
+func (pt *AnimationPlayTable) SetSeatBrightness(direction Direction, brightness float64) error {
+	direction, err := pt.checkDirection(direction)
+	if err != nil {
+		return err
+	}
+	if brightness < 0 || brightness > 1 {
+		return errors.New("brightness out of range")
+	}
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	if brightness == 1 {
+		delete(pt.seatBrightness, direction)
+	} else {
+		pt.seatBrightness[direction] = brightness
+	}
+	return pt.updateFrame()
+}
+
+// GetSeatBrightness returns the brightness of a seat.
+func (pt *AnimationPlayTable) GetSeatBrightness(direction Direction) float64 {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	if brightness, ok := pt.seatBrightness[direction]; ok {
+		return brightness
+	}
+	return 1
+}
+
 // SetActiveDirection sets the active direction
 func (pt *AnimationPlayTable) SetActiveDirection(direction Direction) error {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	return pt.setActiveDirection(direction)
+}
+
+func (pt *AnimationPlayTable) setActiveDirection(direction Direction) error {
 	direction, err := pt.checkDirection(direction)
 	if err != nil {
 		return err
 	}
 	pt.activeDirection = &direction
+	pt.activeDirections = map[Direction]bool{direction: true}
+	pt.simultaneous = false
 	return nil
 }
 
-// ActiveDirectionNext switches to the next active direction
-func (pt *AnimationPlayTable) ActiveDirectionNext() error {
+// GetActiveDirection returns the active direction and false if none is active
+func (pt *AnimationPlayTable) GetActiveDirection() (Direction, bool) {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
 	if pt.activeDirection == nil {
-		return errors.New("no active direction")
-	}
-	for name, direction := range Directions {
-		if direction.start == pt.activeDirection.start && direction.end == pt.activeDirection.end {
-			switch name {
-			case "right":
-				return pt.SetActiveDirection(Directions["bottom"])
-			case "bottom":
-				return pt.SetActiveDirection(Directions["left"])
-			case "left":
-				return pt.SetActiveDirection(Directions["top"])
-			case "top":
-				return pt.SetActiveDirection(Directions["right"])
-			}
-		}
+		return Direction{}, false
 	}
-	return errors.New("active direction does not match known directions")
+	return *pt.activeDirection, true
 }
 
 // ActiveDirectionOff turns active direction off
 func (pt *AnimationPlayTable) ActiveDirectionOff() error {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
 	if pt.activeDirection == nil {
 		return errors.New("no active direction")
 	}
 	pt.activeDirection = nil
-	return nil
+	pt.activeDirections = map[Direction]bool{}
+	pt.simultaneous = false
+	// restore the colors the highlight has changed
+	return pt.updateFrame()
 }
 
@@ -0,0 +1,152 @@
+package table
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestPlayTableConcurrentChanges(t *testing.T) {
+	leds, _, playTable := newTestTable(t)
+	defer leds.StopAnimation()
+	err := playTable.SetScoreTarget(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wait sync.WaitGroup
+	for _, name := range SeatNames {
+		direction := Directions[name]
+		wait.Add(1)
+		go func(direction Direction) {
+			defer wait.Done()
+			for i := 0; i < 200; i++ {
+				playTable.SetSeatBrightness(direction, float64(i%10)/10)
+				playTable.SetSeatState(direction, SeatPassed)
+				playTable.SetSeatState(direction, SeatPlaying)
+				playTable.SetScore(direction, i%10)
+				playTable.AddActiveDirection(direction)
+				playTable.RemoveActiveDirection(direction)
+				playTable.SetPlayerColor(direction, Colors["red"])
+				playTable.GetColormap()
+			}
+		}(direction)
+	}
+	wait.Wait()
+}
+
+func TestPlayTableColormapCopy(t *testing.T) {
+	playTable := NewAnimationPlayTable(&[]byte{}, DefaultLayout)
+	buffer := make([]byte, DefaultLedCount*3)
+	playTable.SetFrameBuffer(&buffer)
+	err := playTable.SetPlayerColorFromString("right=red;10..20=blue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	colormap := playTable.GetColormap()
+	colormap[0].Seat = "changed"
+	colormap[1].Start = 0
+	if got := playTable.GetColormap().String(); got != "right=#ff0000;10..20=#0000ff" {
+		t.Errorf("colormap changed through the copy: %q", got)
+	}
+}
+
+func TestSeatBrightness(t *testing.T) {
+	leds := NewLeds(NewFakeOutput(), DefaultLedCount)
+	playTable := NewAnimationPlayTable(leds.GetFrameBuffer(), DefaultLayout)
+	frameBuffer := leds.GetFrameBuffer()
+	for _, name := range SeatNames {
+		err := playTable.SetPlayerColor(Directions[name], Colors["white"])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		seat       string
+		brightness float64
+		pixel      byte
+		valid      bool
+	}{
+		{"right", 0.5, 127, true},
+		{"bottom", 0, 0, true},
+		{"left", 1, 255, true},
+		{"top", 0.2, 51, true},
+		{"top", -0.1, 51, false},
+		{"top", 1.1, 51, false},
+	}
+	for _, test := range tests {
+		direction := Directions[test.seat]
+		err := playTable.SetSeatBrightness(direction, test.brightness)
+		if (err == nil) != test.valid {
+			t.Errorf("%s at %g: error %v, want valid %v", test.seat, test.brightness, err, test.valid)
+		}
+		if pixel := pixelAt(frameBuffer, direction.start); pixel != (Color{test.pixel, test.pixel, test.pixel}) {
+			t.Errorf("%s at %g: pixel %s", test.seat, test.brightness, pixel.Hex())
+		}
+	}
+	if brightness := playTable.GetSeatBrightness(Directions["right"]); brightness != 0.5 {
+		t.Errorf("right brightness %g, want 0.5", brightness)
+	}
+	if brightness := playTable.GetSeatBrightness(Directions["left"]); brightness != 1 {
+		t.Errorf("left brightness %g, want 1", brightness)
+	}
+	if err := playTable.SetSeatBrightness(Direction{0, 10}, 0.5); err == nil {
+		t.Error("expected an error for a range that is not a seat")
+	}
+	// the seat brightness survives a color change
+	err := playTable.SetPlayerColor(Directions["right"], Colors["red"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pixel := pixelAt(frameBuffer, Directions["right"].start); pixel != (Color{127, 0, 0}) {
+		t.Errorf("right pixel %s after the color change, want #7f0000", pixel.Hex())
+	}
+}
+
+func TestFocusFade(t *testing.T) {
+	leds := NewLeds(NewFakeOutput(), DefaultLedCount)
+	playTable := NewAnimationPlayTable(leds.GetFrameBuffer(), DefaultLayout)
+	frameBuffer := leds.GetFrameBuffer()
+	for _, name := range SeatNames {
+		err := playTable.SetPlayerColor(Directions[name], Colors["white"])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, invalid := range []struct {
+		level     float64
+		fadeSteps int
+	}{{-0.1, 4}, {1.1, 4}, {0.2, 0}} {
+		if err := playTable.SetFocus(true, invalid.level, invalid.fadeSteps); err == nil {
+			t.Errorf("level %g, %d steps: expected an error", invalid.level, invalid.fadeSteps)
+		}
+	}
+	err := playTable.SetFocus(true, 0.2, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enabled, level, fadeSteps := playTable.GetFocus(); !enabled || level != 0.2 || fadeSteps != 4 {
+		t.Errorf("focus %v, %g, %d", enabled, level, fadeSteps)
+	}
+	err = playTable.SetActiveDirection(Directions["left"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	inactive := Directions["right"].start
+	// the inactive seats fade to the focus level within the fade steps
+	for i, want := range []byte{191, 127, 63, 51, 51} {
+		playTable.Step()
+		if pixel := pixelAt(frameBuffer, inactive); pixel != (Color{want, want, want}) {
+			t.Errorf("step %d: inactive pixel %s, want %d", i+1, pixel.Hex(), want)
+		}
+	}
+	// and back once the turn has ended
+	err = playTable.ActiveDirectionOff()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		playTable.Step()
+	}
+	if pixel := pixelAt(frameBuffer, inactive); pixel != Colors["white"] {
+		t.Errorf("inactive pixel %s after the turn, want white", pixel.Hex())
+	}
+}
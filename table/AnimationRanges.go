@@ -0,0 +1,161 @@
+package table
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Corners is a set of predefined ranges between the seats.
+var Corners = map[string]Direction{
+	"bottomright": Direction{40, 45},
+	"bottomleft":  Direction{115, 120},
+	"topleft":     Direction{156, 165},
+	"topright":    Direction{236, 240},
+}
+
+// AnimationRanges paints arbitrary ranges and pixels without any seats.
+type AnimationRanges struct {
+	lock        sync.Mutex
+	frameBuffer *[]byte
+	ranges      []ColormapEntry
+}
+
+// NewAnimationRanges creates a new AnimationRanges.
+func NewAnimationRanges(frameBuffer *[]byte) *AnimationRanges {
+	newAnimation := new(AnimationRanges)
+	newAnimation.frameBuffer = frameBuffer
+	newAnimation.ranges = []ColormapEntry{}
+	return newAnimation
+}
+
+// NewDirection creates a half-open range of pixels.
+func NewDirection(start int, end int) (Direction, error) {
+	if start < 0 || end <= start {
+		return Direction{}, fmt.Errorf("invalid range %d..%d", start, end)
+	}
+	return Direction{start, end}, nil
+}
+
+// Start returns the first pixel of the direction.
+func (d Direction) Start() int {
+	return d.start
+}
+
+// End returns the pixel after the last pixel of the direction.
+func (d Direction) End() int {
+	return d.end
+}
+
+// ParseDirection parses a seat name, a corner name, a range like 41..45 or a
+// single pixel index of the default layout.
+func ParseDirection(encoded string) (Direction, error) {
+	return DefaultLayout.ParseDirection(encoded)
+}
+
+// ParseDirection parses a seat name, a corner name of the layout, a range
+// like 41..45 or a single pixel index.
+func (l Layout) ParseDirection(encoded string) (Direction, error) {
+	entry, err := l.parseColormapTarget(encoded)
+	if err != nil {
+		return Direction{}, err
+	}
+	return entry.Direction(), nil
+}
+
+// SetFrameBuffer sets the frame buffer.
+func (ar *AnimationRanges) SetFrameBuffer(frameBuffer *[]byte) {
+	ar.lock.Lock()
+	defer ar.lock.Unlock()
+	ar.frameBuffer = frameBuffer
+}
+
+// GetFrameBuffer gets the frame buffer.
+func (ar *AnimationRanges) GetFrameBuffer() *[]byte {
+	ar.lock.Lock()
+	defer ar.lock.Unlock()
+	return ar.frameBuffer
+}
+
+// LockFrame locks the frame buffer against changes.
+func (ar *AnimationRanges) LockFrame() {
+	ar.lock.Lock()
+}
+
+// UnlockFrame unlocks the frame buffer.
+func (ar *AnimationRanges) UnlockFrame() {
+	ar.lock.Unlock()
+}
+
+// Step animates one increment.
+func (ar *AnimationRanges) Step() {
+	ar.lock.Lock()
+	defer ar.lock.Unlock()
+	ar.updateFrame()
+}
+
+func (ar *AnimationRanges) updateFrame() error {
+	if ar.frameBuffer == nil {
+		return errors.New("no frame buffer declared")
+	}
+	for i := range *ar.frameBuffer {
+		(*ar.frameBuffer)[i] = 0
+	}
+	for _, entry := range ar.ranges {
+		err := entry.Fill.paint(*ar.frameBuffer, entry.Direction())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetRangeFill paints a range of pixels on top of the ranges painted before.
+func (ar *AnimationRanges) SetRangeFill(direction Direction, fill Fill) error {
+	ar.lock.Lock()
+	defer ar.lock.Unlock()
+	ranges, err := addRange(ar.frameBuffer, ar.ranges, direction, fill)
+	if err != nil {
+		return err
+	}
+	ar.ranges = ranges
+	return ar.updateFrame()
+}
+
+// SetPixelColor sets the color of a single pixel.
+func (ar *AnimationRanges) SetPixelColor(index int, color Color) error {
+	return ar.SetRangeFill(Direction{index, index + 1}, SolidFill(color))
+}
+
+// ClearRanges removes all painted ranges.
+func (ar *AnimationRanges) ClearRanges() {
+	ar.lock.Lock()
+	defer ar.lock.Unlock()
+	ar.ranges = []ColormapEntry{}
+	ar.updateFrame()
+}
+
+// GetColormap exports the painted ranges as a colormap.
+func (ar *AnimationRanges) GetColormap() Colormap {
+	ar.lock.Lock()
+	defer ar.lock.Unlock()
+	return append(Colormap{}, ar.ranges...)
+}
+
+// addRange validates the range against the frame buffer and appends it,
+// replacing an earlier fill of exactly the same range.
+func addRange(frameBuffer *[]byte, ranges []ColormapEntry, direction Direction, fill Fill) ([]ColormapEntry, error) {
+	if direction.start < 0 || direction.end <= direction.start {
+		return ranges, fmt.Errorf("invalid range %d..%d", direction.start, direction.end)
+	}
+	if frameBuffer != nil && direction.end*3 > len(*frameBuffer) {
+		return ranges, fmt.Errorf("range %d..%d exceeds the strip", direction.start, direction.end)
+	}
+	updated := []ColormapEntry{}
+	for _, entry := range ranges {
+		if entry.Direction() != direction {
+			updated = append(updated, entry)
+		}
+	}
+	return append(updated, ColormapEntry{"", direction.start, direction.end, fill}), nil
+}
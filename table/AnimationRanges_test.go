@@ -0,0 +1,131 @@
+package table
+
+import (
+	"testing"
+)
+
+// pixelAt returns the color of a pixel in the frame buffer.
+func pixelAt(frameBuffer *[]byte, index int) Color {
+	return Color{(*frameBuffer)[index*3], (*frameBuffer)[index*3+1], (*frameBuffer)[index*3+2]}
+}
+
+func TestParseDirection(t *testing.T) {
+	tests := []struct {
+		encoded   string
+		direction Direction
+		valid     bool
+	}{
+		{"right", Directions["right"], true},
+		{"TOP", Directions["top"], true},
+		{"topleft", Corners["topleft"], true},
+		{"41..45", Direction{41, 45}, true},
+		{" 7 ", Direction{7, 8}, true},
+		{"45..41", Direction{}, false},
+		{"-1", Direction{}, false},
+		{"1..x", Direction{}, false},
+		{"kitchen", Direction{}, false},
+	}
+	for _, test := range tests {
+		direction, err := ParseDirection(test.encoded)
+		if (err == nil) != test.valid {
+			t.Errorf("%q: error %v, want valid %v", test.encoded, err, test.valid)
+			continue
+		}
+		if test.valid && direction != test.direction {
+			t.Errorf("%q: range %d..%d, want %d..%d", test.encoded, direction.start, direction.end, test.direction.start, test.direction.end)
+		}
+	}
+}
+
+func TestAnimationRanges(t *testing.T) {
+	frameBuffer := make([]byte, 20*3)
+	ranges := NewAnimationRanges(&frameBuffer)
+	red, blue, white := Colors["red"], Colors["blue"], Colors["white"]
+	err := ranges.SetRangeFill(Direction{0, 10}, SolidFill(red))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// later ranges are painted on top
+	err = ranges.SetRangeFill(Direction{5, 15}, SolidFill(blue))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ranges.SetPixelColor(19, white)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		index int
+		color Color
+	}{
+		{0, red}, {4, red}, {5, blue}, {14, blue}, {15, Color{}}, {19, white},
+	}
+	for _, test := range tests {
+		if color := pixelAt(&frameBuffer, test.index); color != test.color {
+			t.Errorf("pixel %d: %s, want %s", test.index, color.Hex(), test.color.Hex())
+		}
+	}
+	// painting the same range again replaces it
+	err = ranges.SetRangeFill(Direction{0, 10}, SolidFill(white))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if colormap := ranges.GetColormap().String(); colormap != "5..15=#0000ff;19=#ffffff;0..10=#ffffff" {
+		t.Errorf("colormap %q", colormap)
+	}
+	if err = ranges.SetRangeFill(Direction{15, 21}, SolidFill(red)); err == nil {
+		t.Error("expected an error painting beyond the strip")
+	}
+	if err = ranges.SetRangeFill(Direction{10, 10}, SolidFill(red)); err == nil {
+		t.Error("expected an error painting an empty range")
+	}
+	ranges.ClearRanges()
+	for i := 0; i < 20; i++ {
+		if color := pixelAt(&frameBuffer, i); color != (Color{}) {
+			t.Fatalf("pixel %d is %s after clearing", i, color.Hex())
+		}
+	}
+}
+
+func TestPlayTableRanges(t *testing.T) {
+	leds := NewLeds(NewFakeOutput(), DefaultLedCount)
+	playTable := NewAnimationPlayTable(leds.GetFrameBuffer(), DefaultLayout)
+	frameBuffer := leds.GetFrameBuffer()
+	err := playTable.SetPlayerColor(Directions["right"], Colors["red"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a range covering exactly a seat sets the seat
+	err = playTable.SetRangeFill(Directions["bottom"], SolidFill(Colors["green"]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = playTable.SetRangeFill(Corners["bottomright"], SolidFill(Colors["white"]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = playTable.SetPixelColor(10, Colors["blue"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if colormap := playTable.GetColormap().String(); colormap != "right=#ff0000;bottom=#00ff00;40..45=#ffffff;10=#0000ff" {
+		t.Errorf("colormap %q", colormap)
+	}
+	// ranges are painted on top of the seats
+	if color := pixelAt(frameBuffer, 10); color != Colors["blue"] {
+		t.Errorf("pixel 10 is %s, want blue on top of the seat", color.Hex())
+	}
+	if color := pixelAt(frameBuffer, 11); color != Colors["red"] {
+		t.Errorf("pixel 11 is %s, want the red seat", color.Hex())
+	}
+	playTable.ClearRanges()
+	if color := pixelAt(frameBuffer, 42); color != (Color{}) {
+		t.Errorf("corner pixel is %s after clearing", color.Hex())
+	}
+	if color := pixelAt(frameBuffer, 10); color != Colors["red"] {
+		t.Errorf("pixel 10 is %s after clearing, want the red seat", color.Hex())
+	}
+	if colormap := playTable.GetColormap().String(); colormap != "right=#ff0000;bottom=#00ff00" {
+		t.Errorf("colormap %q after clearing", colormap)
+	}
+}
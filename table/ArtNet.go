@@ -0,0 +1,81 @@
+package table
+
+// ArtNetDefaultPort is the UDP port of Art-Net.
+const ArtNetDefaultPort = 6454
+
+const artNetOpDmx = 0x5000
+const artNetProtocolVersion = 14
+
+// ArtNet sends frames as Art-Net ArtDmx packets, one universe per 170
+// LEDs starting at the configured port address.
+type ArtNet struct {
+	udpOutput
+	universe int
+	sequence byte
+}
+
+// NewArtNet creates an Art-Net output. Use the broadcast address of the
+// network as host to reach all nodes.
+func NewArtNet(host string, port int, universe int) (*ArtNet, error) {
+	if port == 0 {
+		port = ArtNetDefaultPort
+	}
+	artNet := new(ArtNet)
+	artNet.host = host
+	artNet.port = port
+	artNet.universe = universe
+	err := artNet.Connect()
+	if err != nil {
+		return nil, err
+	}
+	return artNet, nil
+}
+
+// String returns the address of the node.
+func (a *ArtNet) String() string {
+	return "artnet " + a.address()
+}
+
+// StartStream does nothing, nodes show frames as they arrive.
+func (a *ArtNet) StartStream() error {
+	return nil
+}
+
+// SendFrame sends one ArtDmx packet per universe.
+func (a *ArtNet) SendFrame(frame []byte) error {
+	// sequence 0 disables reordering on the node
+	a.sequence = a.sequence%255 + 1
+	for i := 0; i*dmxUniverseLeds*3 < len(frame); i++ {
+		start := i * dmxUniverseLeds * 3
+		end := start + dmxUniverseLeds*3
+		if end > len(frame) {
+			end = len(frame)
+		}
+		data := frame[start:end]
+		// the data length has to be even
+		length := len(data) + len(data)%2
+		universe := a.universe + i
+		packet := []byte("Art-Net\x00")
+		packet = append(packet, byte(artNetOpDmx&0xff), byte(artNetOpDmx>>8), 0x00, artNetProtocolVersion)
+		packet = append(packet, a.sequence, 0x00, byte(universe), byte(universe>>8)&0x7f, byte(length>>8), byte(length))
+		packet = append(packet, data...)
+		if length > len(data) {
+			packet = append(packet, 0x00)
+		}
+		err := a.send(packet)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SupportsBrightness returns false, Art-Net has no brightness setting.
+func (a *ArtNet) SupportsBrightness() bool {
+	return false
+}
+
+// SetBrightness is not supported by Art-Net.
+func (a *ArtNet) SetBrightness(value byte) error {
+	return ErrBrightnessUnsupported
+}
@@ -0,0 +1,344 @@
+package table
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const minKelvin = 1000
+const maxKelvin = 40000
+
+// CSSColors is the set of named colors defined by CSS Color Module Level 4.
+var CSSColors = map[string]Color{
+	"aliceblue":            Color{0xf0, 0xf8, 0xff},
+	"antiquewhite":         Color{0xfa, 0xeb, 0xd7},
+	"aqua":                 Color{0x00, 0xff, 0xff},
+	"aquamarine":           Color{0x7f, 0xff, 0xd4},
+	"azure":                Color{0xf0, 0xff, 0xff},
+	"beige":                Color{0xf5, 0xf5, 0xdc},
+	"bisque":               Color{0xff, 0xe4, 0xc4},
+	"black":                Color{0x00, 0x00, 0x00},
+	"blanchedalmond":       Color{0xff, 0xeb, 0xcd},
+	"blue":                 Color{0x00, 0x00, 0xff},
+	"blueviolet":           Color{0x8a, 0x2b, 0xe2},
+	"brown":                Color{0xa5, 0x2a, 0x2a},
+	"burlywood":            Color{0xde, 0xb8, 0x87},
+	"cadetblue":            Color{0x5f, 0x9e, 0xa0},
+	"chartreuse":           Color{0x7f, 0xff, 0x00},
+	"chocolate":            Color{0xd2, 0x69, 0x1e},
+	"coral":                Color{0xff, 0x7f, 0x50},
+	"cornflowerblue":       Color{0x64, 0x95, 0xed},
+	"cornsilk":             Color{0xff, 0xf8, 0xdc},
+	"crimson":              Color{0xdc, 0x14, 0x3c},
+	"cyan":                 Color{0x00, 0xff, 0xff},
+	"darkblue":             Color{0x00, 0x00, 0x8b},
+	"darkcyan":             Color{0x00, 0x8b, 0x8b},
+	"darkgoldenrod":        Color{0xb8, 0x86, 0x0b},
+	"darkgray":             Color{0xa9, 0xa9, 0xa9},
+	"darkgreen":            Color{0x00, 0x64, 0x00},
+	"darkgrey":             Color{0xa9, 0xa9, 0xa9},
+	"darkkhaki":            Color{0xbd, 0xb7, 0x6b},
+	"darkmagenta":          Color{0x8b, 0x00, 0x8b},
+	"darkolivegreen":       Color{0x55, 0x6b, 0x2f},
+	"darkorange":           Color{0xff, 0x8c, 0x00},
+	"darkorchid":           Color{0x99, 0x32, 0xcc},
+	"darkred":              Color{0x8b, 0x00, 0x00},
+	"darksalmon":           Color{0xe9, 0x96, 0x7a},
+	"darkseagreen":         Color{0x8f, 0xbc, 0x8f},
+	"darkslateblue":        Color{0x48, 0x3d, 0x8b},
+	"darkslategray":        Color{0x2f, 0x4f, 0x4f},
+	"darkslategrey":        Color{0x2f, 0x4f, 0x4f},
+	"darkturquoise":        Color{0x00, 0xce, 0xd1},
+	"darkviolet":           Color{0x94, 0x00, 0xd3},
+	"deeppink":             Color{0xff, 0x14, 0x93},
+	"deepskyblue":          Color{0x00, 0xbf, 0xff},
+	"dimgray":              Color{0x69, 0x69, 0x69},
+	"dimgrey":              Color{0x69, 0x69, 0x69},
+	"dodgerblue":           Color{0x1e, 0x90, 0xff},
+	"firebrick":            Color{0xb2, 0x22, 0x22},
+	"floralwhite":          Color{0xff, 0xfa, 0xf0},
+	"forestgreen":          Color{0x22, 0x8b, 0x22},
+	"fuchsia":              Color{0xff, 0x00, 0xff},
+	"gainsboro":            Color{0xdc, 0xdc, 0xdc},
+	"ghostwhite":           Color{0xf8, 0xf8, 0xff},
+	"gold":                 Color{0xff, 0xd7, 0x00},
+	"goldenrod":            Color{0xda, 0xa5, 0x20},
+	"gray":                 Color{0x80, 0x80, 0x80},
+	"green":                Color{0x00, 0x80, 0x00},
+	"greenyellow":          Color{0xad, 0xff, 0x2f},
+	"grey":                 Color{0x80, 0x80, 0x80},
+	"honeydew":             Color{0xf0, 0xff, 0xf0},
+	"hotpink":              Color{0xff, 0x69, 0xb4},
+	"indianred":            Color{0xcd, 0x5c, 0x5c},
+	"indigo":               Color{0x4b, 0x00, 0x82},
+	"ivory":                Color{0xff, 0xff, 0xf0},
+	"khaki":                Color{0xf0, 0xe6, 0x8c},
+	"lavender":             Color{0xe6, 0xe6, 0xfa},
+	"lavenderblush":        Color{0xff, 0xf0, 0xf5},
+	"lawngreen":            Color{0x7c, 0xfc, 0x00},
+	"lemonchiffon":         Color{0xff, 0xfa, 0xcd},
+	"lightblue":            Color{0xad, 0xd8, 0xe6},
+	"lightcoral":           Color{0xf0, 0x80, 0x80},
+	"lightcyan":            Color{0xe0, 0xff, 0xff},
+	"lightgoldenrodyellow": Color{0xfa, 0xfa, 0xd2},
+	"lightgray":            Color{0xd3, 0xd3, 0xd3},
+	"lightgreen":           Color{0x90, 0xee, 0x90},
+	"lightgrey":            Color{0xd3, 0xd3, 0xd3},
+	"lightpink":            Color{0xff, 0xb6, 0xc1},
+	"lightsalmon":          Color{0xff, 0xa0, 0x7a},
+	"lightseagreen":        Color{0x20, 0xb2, 0xaa},
+	"lightskyblue":         Color{0x87, 0xce, 0xfa},
+	"lightslategray":       Color{0x77, 0x88, 0x99},
+	"lightslategrey":       Color{0x77, 0x88, 0x99},
+	"lightsteelblue":       Color{0xb0, 0xc4, 0xde},
+	"lightyellow":          Color{0xff, 0xff, 0xe0},
+	"lime":                 Color{0x00, 0xff, 0x00},
+	"limegreen":            Color{0x32, 0xcd, 0x32},
+	"linen":                Color{0xfa, 0xf0, 0xe6},
+	"magenta":              Color{0xff, 0x00, 0xff},
+	"maroon":               Color{0x80, 0x00, 0x00},
+	"mediumaquamarine":     Color{0x66, 0xcd, 0xaa},
+	"mediumblue":           Color{0x00, 0x00, 0xcd},
+	"mediumorchid":         Color{0xba, 0x55, 0xd3},
+	"mediumpurple":         Color{0x93, 0x70, 0xdb},
+	"mediumseagreen":       Color{0x3c, 0xb3, 0x71},
+	"mediumslateblue":      Color{0x7b, 0x68, 0xee},
+	"mediumspringgreen":    Color{0x00, 0xfa, 0x9a},
+	"mediumturquoise":      Color{0x48, 0xd1, 0xcc},
+	"mediumvioletred":      Color{0xc7, 0x15, 0x85},
+	"midnightblue":         Color{0x19, 0x19, 0x70},
+	"mintcream":            Color{0xf5, 0xff, 0xfa},
+	"mistyrose":            Color{0xff, 0xe4, 0xe1},
+	"moccasin":             Color{0xff, 0xe4, 0xb5},
+	"navajowhite":          Color{0xff, 0xde, 0xad},
+	"navy":                 Color{0x00, 0x00, 0x80},
+	"oldlace":              Color{0xfd, 0xf5, 0xe6},
+	"olive":                Color{0x80, 0x80, 0x00},
+	"olivedrab":            Color{0x6b, 0x8e, 0x23},
+	"orange":               Color{0xff, 0xa5, 0x00},
+	"orangered":            Color{0xff, 0x45, 0x00},
+	"orchid":               Color{0xda, 0x70, 0xd6},
+	"palegoldenrod":        Color{0xee, 0xe8, 0xaa},
+	"palegreen":            Color{0x98, 0xfb, 0x98},
+	"paleturquoise":        Color{0xaf, 0xee, 0xee},
+	"palevioletred":        Color{0xdb, 0x70, 0x93},
+	"papayawhip":           Color{0xff, 0xef, 0xd5},
+	"peachpuff":            Color{0xff, 0xda, 0xb9},
+	"peru":                 Color{0xcd, 0x85, 0x3f},
+	"pink":                 Color{0xff, 0xc0, 0xcb},
+	"plum":                 Color{0xdd, 0xa0, 0xdd},
+	"powderblue":           Color{0xb0, 0xe0, 0xe6},
+	"purple":               Color{0x80, 0x00, 0x80},
+	"rebeccapurple":        Color{0x66, 0x33, 0x99},
+	"red":                  Color{0xff, 0x00, 0x00},
+	"rosybrown":            Color{0xbc, 0x8f, 0x8f},
+	"royalblue":            Color{0x41, 0x69, 0xe1},
+	"saddlebrown":          Color{0x8b, 0x45, 0x13},
+	"salmon":               Color{0xfa, 0x80, 0x72},
+	"sandybrown":           Color{0xf4, 0xa4, 0x60},
+	"seagreen":             Color{0x2e, 0x8b, 0x57},
+	"seashell":             Color{0xff, 0xf5, 0xee},
+	"sienna":               Color{0xa0, 0x52, 0x2d},
+	"silver":               Color{0xc0, 0xc0, 0xc0},
+	"skyblue":              Color{0x87, 0xce, 0xeb},
+	"slateblue":            Color{0x6a, 0x5a, 0xcd},
+	"slategray":            Color{0x70, 0x80, 0x90},
+	"slategrey":            Color{0x70, 0x80, 0x90},
+	"snow":                 Color{0xff, 0xfa, 0xfa},
+	"springgreen":          Color{0x00, 0xff, 0x7f},
+	"steelblue":            Color{0x46, 0x82, 0xb4},
+	"tan":                  Color{0xd2, 0xb4, 0x8c},
+	"teal":                 Color{0x00, 0x80, 0x80},
+	"thistle":              Color{0xd8, 0xbf, 0xd8},
+	"tomato":               Color{0xff, 0x63, 0x47},
+	"turquoise":            Color{0x40, 0xe0, 0xd0},
+	"violet":               Color{0xee, 0x82, 0xee},
+	"wheat":                Color{0xf5, 0xde, 0xb3},
+	"white":                Color{0xff, 0xff, 0xff},
+	"whitesmoke":           Color{0xf5, 0xf5, 0xf5},
+	"yellow":               Color{0xff, 0xff, 0x00},
+	"yellowgreen":          Color{0x9a, 0xcd, 0x32},
+}
+
+// NewColor creates a color from its components.
+func NewColor(r, g, b byte) Color {
+	return Color{r, g, b}
+}
+
+// RGB returns the components of the color.
+func (c Color) RGB() (byte, byte, byte) {
+	return c.r, c.g, c.b
+}
+
+// Hex returns the color in #rrggbb notation.
+func (c Color) Hex() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.r, c.g, c.b)
+}
+
+// ParseColor parses a color given as #rrggbb, #rgb, rgb(r,g,b), hsv(h,s,v),
+// hsl(h,s,l), a color temperature like 2700K or a color name. Names from
+// Colors take precedence over the CSS color names so existing setups keep
+// their colors.
+func ParseColor(input string) (Color, error) {
+	value := strings.ToLower(strings.TrimSpace(input))
+	if value == "" {
+		return Color{}, errors.New("empty color")
+	}
+	if color, ok := Colors[value]; ok {
+		return color, nil
+	}
+	if color, ok := CSSColors[value]; ok {
+		return color, nil
+	}
+	switch {
+	case strings.HasPrefix(value, "#"):
+		return parseHexColor(value[1:])
+	case strings.HasPrefix(value, "rgb(") && strings.HasSuffix(value, ")"):
+		return parseRGBColor(value[4 : len(value)-1])
+	case strings.HasPrefix(value, "hsv(") && strings.HasSuffix(value, ")"):
+		h, s, v, err := parseHueTriple(value[4 : len(value)-1])
+		if err != nil {
+			return Color{}, fmt.Errorf("invalid hsv color %q: %v", input, err)
+		}
+		return hsvToColor(h, s, v), nil
+	case strings.HasPrefix(value, "hsl(") && strings.HasSuffix(value, ")"):
+		h, s, l, err := parseHueTriple(value[4 : len(value)-1])
+		if err != nil {
+			return Color{}, fmt.Errorf("invalid hsl color %q: %v", input, err)
+		}
+		return hslToColor(h, s, l), nil
+	case strings.HasSuffix(value, "k"):
+		kelvin, err := strconv.Atoi(value[:len(value)-1])
+		if err != nil {
+			return Color{}, fmt.Errorf("invalid color temperature %q", input)
+		}
+		return KelvinToColor(kelvin)
+	}
+	return Color{}, fmt.Errorf("unknown color %q", input)
+}
+
+func parseHexColor(hex string) (Color, error) {
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return Color{}, fmt.Errorf("invalid hex color #%s", hex)
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return Color{}, fmt.Errorf("invalid hex color #%s", hex)
+	}
+	return Color{byte(value >> 16), byte(value >> 8), byte(value)}, nil
+}
+
+func parseRGBColor(components string) (Color, error) {
+	parts := strings.Split(components, ",")
+	if len(parts) != 3 {
+		return Color{}, fmt.Errorf("invalid rgb color rgb(%s)", components)
+	}
+	var rgb [3]byte
+	for i, part := range parts {
+		value, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || value < 0 || value > 255 {
+			return Color{}, fmt.Errorf("invalid rgb component %q", part)
+		}
+		rgb[i] = byte(value)
+	}
+	return Color{rgb[0], rgb[1], rgb[2]}, nil
+}
+
+// parseHueTriple parses "h,a,b" where h is in degrees and a and b are
+// percentages, with or without the % sign.
+func parseHueTriple(components string) (float64, float64, float64, error) {
+	parts := strings.Split(components, ",")
+	if len(parts) != 3 {
+		return 0, 0, 0, errors.New("expected three components")
+	}
+	hue, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(parts[0]), "deg"), 64)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid hue %q", parts[0])
+	}
+	var percentages [2]float64
+	for i, part := range parts[1:] {
+		value, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(part), "%"), 64)
+		if err != nil || value < 0 || value > 100 {
+			return 0, 0, 0, fmt.Errorf("invalid percentage %q", part)
+		}
+		percentages[i] = value / 100
+	}
+	hue = math.Mod(hue, 360)
+	if hue < 0 {
+		hue += 360
+	}
+	return hue, percentages[0], percentages[1], nil
+}
+
+func colorFromFloats(r, g, b float64) Color {
+	return Color{floatToByte(r), floatToByte(g), floatToByte(b)}
+}
+
+func floatToByte(value float64) byte {
+	return byte(math.Round(math.Max(0, math.Min(1, value)) * 255))
+}
+
+func hsvToColor(h, s, v float64) Color {
+	c := v * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := v - c
+	r, g, b := hueSector(h, c, x)
+	return colorFromFloats(r+m, g+m, b+m)
+}
+
+func hslToColor(h, s, l float64) Color {
+	c := (1 - math.Abs(2*l-1)) * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := l - c/2
+	r, g, b := hueSector(h, c, x)
+	return colorFromFloats(r+m, g+m, b+m)
+}
+
+func hueSector(h, c, x float64) (float64, float64, float64) {
+	switch {
+	case h < 60:
+		return c, x, 0
+	case h < 120:
+		return x, c, 0
+	case h < 180:
+		return 0, c, x
+	case h < 240:
+		return 0, x, c
+	case h < 300:
+		return x, 0, c
+	default:
+		return c, 0, x
+	}
+}
+
+// KelvinToColor approximates the color of a black body at the given
+// temperature, following Tanner Helland's curve fit.
+func KelvinToColor(kelvin int) (Color, error) {
+	if kelvin < minKelvin || kelvin > maxKelvin {
+		return Color{}, fmt.Errorf("color temperature %dK out of range %dK-%dK", kelvin, minKelvin, maxKelvin)
+	}
+	temperature := float64(kelvin) / 100
+	var r, g, b float64
+	if temperature <= 66 {
+		r = 255
+		g = 99.4708025861*math.Log(temperature) - 161.1195681661
+	} else {
+		r = 329.698727446 * math.Pow(temperature-60, -0.1332047592)
+		g = 288.1221695283 * math.Pow(temperature-60, -0.0755148492)
+	}
+	switch {
+	case temperature >= 66:
+		b = 255
+	case temperature <= 19:
+		b = 0
+	default:
+		b = 138.5177312231*math.Log(temperature-10) - 305.0447927307
+	}
+	return colorFromFloats(r/255, g/255, b/255), nil
+}
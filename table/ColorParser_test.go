@@ -0,0 +1,90 @@
+package table
+
+import (
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		input string
+		color Color
+		valid bool
+	}{
+		{"#ff8000", Color{0xff, 0x80, 0x00}, true},
+		{"#FF8000", Color{0xff, 0x80, 0x00}, true},
+		{" #123abc ", Color{0x12, 0x3a, 0xbc}, true},
+		{"#f80", Color{0xff, 0x88, 0x00}, true},
+		{"#ff80", Color{}, false},
+		{"#gg0000", Color{}, false},
+		{"rgb(255,128,0)", Color{255, 128, 0}, true},
+		{"rgb( 1 , 2 , 3 )", Color{1, 2, 3}, true},
+		{"rgb(256,0,0)", Color{}, false},
+		{"rgb(1,2)", Color{}, false},
+		{"hsv(0,100,100)", Color{255, 0, 0}, true},
+		{"hsv(120,100%,50%)", Color{0, 128, 0}, true},
+		{"hsv(240deg,100,100)", Color{0, 0, 255}, true},
+		{"hsv(360,100,100)", Color{255, 0, 0}, true},
+		{"hsv(-120,100,100)", Color{0, 0, 255}, true},
+		{"hsv(0,0,100)", Color{255, 255, 255}, true},
+		{"hsv(0,101,100)", Color{}, false},
+		{"hsl(0,100,50)", Color{255, 0, 0}, true},
+		{"hsl(120,100%,25%)", Color{0, 128, 0}, true},
+		{"hsl(0,0,100)", Color{255, 255, 255}, true},
+		{"hsl(0,0,0)", Color{0, 0, 0}, true},
+		{"hsl(x,0,0)", Color{}, false},
+		{"1000K", Color{255, 68, 0}, true},
+		{"6600k", Color{255, 255, 255}, true},
+		{"40000K", Color{152, 186, 255}, true},
+		{"999K", Color{}, false},
+		{"40001K", Color{}, false},
+		{"warmK", Color{}, false},
+		{"rebeccapurple", Color{0x66, 0x33, 0x99}, true},
+		{"Navy", Color{0x00, 0x00, 0x80}, true},
+		{"blurple", Color{}, false},
+		{"", Color{}, false},
+	}
+	for _, test := range tests {
+		color, err := ParseColor(test.input)
+		if (err == nil) != test.valid {
+			t.Errorf("%q: error %v, want valid %v", test.input, err, test.valid)
+			continue
+		}
+		if test.valid && color != test.color {
+			t.Errorf("%q: color %s, want %s", test.input, color.Hex(), test.color.Hex())
+		}
+	}
+}
+
+// the predefined Colors win over CSS colors of the same name
+func TestParseColorNameCollisions(t *testing.T) {
+	collisions := 0
+	for name, color := range Colors {
+		parsed, err := ParseColor(name)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if parsed != color {
+			t.Errorf("%s: color %s, want the predefined %s", name, parsed.Hex(), color.Hex())
+		}
+		if css, ok := CSSColors[name]; ok && css != color {
+			collisions++
+		}
+	}
+	if collisions == 0 {
+		t.Error("expected predefined colors differing from CSS")
+	}
+	tests := []struct {
+		name  string
+		color Color
+	}{
+		{"purple", Color{0xff, 0x00, 0xbf}},
+		{"green", Color{0x00, 0xff, 0x00}},
+		{"orange", Color{0xff, 0x80, 0x00}},
+	}
+	for _, test := range tests {
+		if color, _ := ParseColor(test.name); color != test.color {
+			t.Errorf("%s: color %s, want %s", test.name, color.Hex(), test.color.Hex())
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package table
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Colormap is an ordered list of colormap entries. The text format is
+//
+//	colormap := entry (";" entry)*
+//	entry    := target "=" fill
+//	target   := seat | corner | start ".." end | pixel
+//	fill     := color | "gradient(" stop ("," stop)+ ("," option)* ")"
+//	stop     := color [position "%"]
+//	option   := "mirror" | "rgb" | "hsv" | "oklab"
+//
+// where seat is one of the seats of the layout, corner one of its corners,
+// ranges are half-open like Directions and color is anything accepted by
+// ParseColor. The legacy format s,e,r,g,b[-s,e,r,g,b]* is accepted as well.
+type Colormap []ColormapEntry
+
+// ColormapEntry assigns a fill to a seat or a range of pixels.
+type ColormapEntry struct {
+	Seat  string
+	Start int
+	End   int
+	Fill  Fill
+}
+
+// ColormapError describes an invalid colormap entry.
+type ColormapError struct {
+	Entry    int
+	Position int
+	Text     string
+	Message  string
+}
+
+func (e *ColormapError) Error() string {
+	return fmt.Sprintf("colormap entry %d (%q) at position %d: %s", e.Entry, e.Text, e.Position, e.Message)
+}
+
+// SeatNames lists the predefined seats in clockwise order.
+var SeatNames = []string{"right", "bottom", "left", "top"}
+
+// Direction returns the range covered by the entry.
+func (e ColormapEntry) Direction() Direction {
+	return Direction{e.Start, e.End}
+}
+
+// ParseColormap parses a string-encoded colormap for the default layout.
+func ParseColormap(encoded string) (Colormap, error) {
+	return DefaultLayout.ParseColormap(encoded)
+}
+
+// ParseColormap parses a string-encoded colormap with the seats and corners
+// of the layout.
+func (l Layout) ParseColormap(encoded string) (Colormap, error) {
+	if !strings.Contains(encoded, "=") {
+		return l.parseLegacyColormap(encoded)
+	}
+	colormap := Colormap{}
+	for i, token := range splitTopLevel(encoded, ';') {
+		entry, err := l.parseColormapEntry(token.text)
+		if err != nil {
+			return nil, &ColormapError{i + 1, token.position, strings.TrimSpace(token.text), err.Error()}
+		}
+		colormap = append(colormap, entry)
+	}
+	return colormap, nil
+}
+
+func (l Layout) parseColormapEntry(text string) (ColormapEntry, error) {
+	parts := splitTopLevel(text, '=')
+	if len(parts) != 2 {
+		return ColormapEntry{}, fmt.Errorf("expected target=fill")
+	}
+	entry, err := l.parseColormapTarget(strings.TrimSpace(parts[0].text))
+	if err != nil {
+		return entry, err
+	}
+	entry.Fill, err = ParseFill(parts[1].text)
+	return entry, err
+}
+
+func (l Layout) parseColormapTarget(target string) (ColormapEntry, error) {
+	if direction, ok := l.Seats[strings.ToLower(target)]; ok {
+		return ColormapEntry{strings.ToLower(target), direction.start, direction.end, Fill{}}, nil
+	}
+	if direction, ok := l.Corners[strings.ToLower(target)]; ok {
+		return ColormapEntry{"", direction.start, direction.end, Fill{}}, nil
+	}
+	direction, err := parseRange(target)
+	if err != nil {
+		return ColormapEntry{}, err
+	}
+	return ColormapEntry{l.SeatName(direction), direction.start, direction.end, Fill{}}, nil
+}
+
+// parseRange parses a range like 41..45 or a single pixel index.
+func parseRange(target string) (Direction, error) {
+	var start, end int
+	var err error
+	if bounds := strings.SplitN(target, "..", 2); len(bounds) == 2 {
+		start, err = strconv.Atoi(strings.TrimSpace(bounds[0]))
+		if err != nil {
+			return Direction{}, fmt.Errorf("invalid range start %q", bounds[0])
+		}
+		end, err = strconv.Atoi(strings.TrimSpace(bounds[1]))
+		if err != nil {
+			return Direction{}, fmt.Errorf("invalid range end %q", bounds[1])
+		}
+	} else {
+		start, err = strconv.Atoi(strings.TrimSpace(target))
+		if err != nil {
+			return Direction{}, fmt.Errorf("unknown seat or range %q", target)
+		}
+		end = start + 1
+	}
+	return NewDirection(start, end)
+}
+
+// ParseFill parses a single color or a gradient(...) expression. Gradient
+// stops may be followed by a position like "blue 30%", the keywords mirror,
+// rgb, hsv and oklab select mirroring and the interpolation color space.
+func ParseFill(encoded string) (Fill, error) {
+	value := strings.TrimSpace(encoded)
+	lower := strings.ToLower(value)
+	if strings.HasPrefix(lower, "gradient(") && strings.HasSuffix(lower, ")") {
+		stops := []Color{}
+		positions := []float64{}
+		mirror := false
+		space := ColorSpaceRGB
+		for _, token := range splitTopLevel(value[len("gradient("):len(value)-1], ',') {
+			text := strings.ToLower(strings.TrimSpace(token.text))
+			switch text {
+			case "mirror":
+				mirror = true
+				continue
+			case string(ColorSpaceRGB), string(ColorSpaceHSV), string(ColorSpaceOKLab):
+				space = ColorSpace(text)
+				continue
+			}
+			position := -1.0
+			if split := strings.LastIndex(text, " "); split > 0 && strings.HasSuffix(text, "%") {
+				percentage, err := strconv.ParseFloat(text[split+1:len(text)-1], 64)
+				if err != nil || percentage < 0 || percentage > 100 {
+					return Fill{}, fmt.Errorf("invalid gradient position %q", text[split+1:])
+				}
+				position = percentage / 100
+				text = text[:split]
+			}
+			color, err := ParseColor(text)
+			if err != nil {
+				return Fill{}, err
+			}
+			stops = append(stops, color)
+			positions = append(positions, position)
+		}
+		return NewGradientFill(stops, positions, mirror, space)
+	}
+	color, err := ParseColor(value)
+	if err != nil {
+		return Fill{}, err
+	}
+	return SolidFill(color), nil
+}
+
+func (l Layout) parseLegacyColormap(encoded string) (Colormap, error) {
+	colormap := Colormap{}
+	for i, token := range splitTopLevel(encoded, '-') {
+		var start, end int
+		var colorR, colorG, colorB byte
+		var rest string
+		n, _ := fmt.Sscanf(token.text+" .", "%d,%d,%x,%x,%x %s", &start, &end, &colorR, &colorG, &colorB, &rest)
+		if n != 6 || rest != "." {
+			return nil, &ColormapError{i + 1, token.position, token.text, "expected s,e,r,g,b"}
+		}
+		if start < 0 || end <= start {
+			return nil, &ColormapError{i + 1, token.position, token.text, fmt.Sprintf("invalid range %d..%d", start, end)}
+		}
+		colormap = append(colormap, ColormapEntry{l.SeatName(Direction{start, end}), start, end, SolidFill(Color{colorR, colorG, colorB})})
+	}
+	return colormap, nil
+}
+
+// String serializes the colormap in the format accepted by ParseColormap.
+func (cm Colormap) String() string {
+	entries := []string{}
+	for _, entry := range cm {
+		entries = append(entries, entry.String())
+	}
+	return strings.Join(entries, ";")
+}
+
+// String serializes a single entry.
+func (e ColormapEntry) String() string {
+	target := e.Seat
+	if target == "" {
+		if e.End == e.Start+1 {
+			target = strconv.Itoa(e.Start)
+		} else {
+			target = fmt.Sprintf("%d..%d", e.Start, e.End)
+		}
+	}
+	return target + "=" + e.Fill.String()
+}
+
+// String serializes the fill in the format accepted by ParseFill.
+func (f Fill) String() string {
+	if len(f.stops) == 1 {
+		return f.stops[0].Hex()
+	}
+	parts := []string{}
+	if f.space != ColorSpaceRGB {
+		parts = append(parts, string(f.space))
+	}
+	if f.mirror {
+		parts = append(parts, "mirror")
+	}
+	for i, stop := range f.stops {
+		even := float64(i) / float64(len(f.stops)-1)
+		if math.Abs(f.positions[i]-even) > 1e-9 {
+			parts = append(parts, fmt.Sprintf("%s %s%%", stop.Hex(), strconv.FormatFloat(f.positions[i]*100, 'f', -1, 64)))
+		} else {
+			parts = append(parts, stop.Hex())
+		}
+	}
+	return "gradient(" + strings.Join(parts, ",") + ")"
+}
+
+type colormapToken struct {
+	text     string
+	position int
+}
+
+// splitTopLevel splits input at separator, ignoring separators within parentheses.
+func splitTopLevel(input string, separator byte) []colormapToken {
+	tokens := []colormapToken{}
+	depth := 0
+	start := 0
+	for i := 0; i < len(input); i++ {
+		switch input[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case separator:
+			if depth == 0 {
+				tokens = append(tokens, colormapToken{input[start:i], start})
+				start = i + 1
+			}
+		}
+	}
+	return append(tokens, colormapToken{input[start:], start})
+}
@@ -0,0 +1,115 @@
+package table
+
+import (
+	"testing"
+)
+
+func TestParseColormap(t *testing.T) {
+	tests := []struct {
+		encoded string
+		entries []ColormapEntry
+		valid   bool
+	}{
+		{"right=red", []ColormapEntry{{"right", 0, 40, SolidFill(Color{255, 0, 0})}}, true},
+		{"Right = #00ff00 ; 10..20=blue", []ColormapEntry{{"right", 0, 40, SolidFill(Color{0, 255, 0})}, {"", 10, 20, SolidFill(Color{0, 0, 255})}}, true},
+		{"7=white", []ColormapEntry{{"", 7, 8, SolidFill(Color{255, 255, 255})}}, true},
+		{"0..40=red", []ColormapEntry{{"right", 0, 40, SolidFill(Color{255, 0, 0})}}, true},
+		{"0,40,ff,00,00-50,60,00,00,ff", []ColormapEntry{{"right", 0, 40, SolidFill(Color{255, 0, 0})}, {"", 50, 60, SolidFill(Color{0, 0, 255})}}, true},
+		{"kitchen=red", nil, false},
+		{"right", nil, false},
+		{"right=blurple", nil, false},
+		{"20..10=red", nil, false},
+		{"0,41,ff,00", nil, false},
+	}
+	for _, test := range tests {
+		colormap, err := ParseColormap(test.encoded)
+		if (err == nil) != test.valid {
+			t.Errorf("%q: error %v, want valid %v", test.encoded, err, test.valid)
+			continue
+		}
+		if !test.valid {
+			continue
+		}
+		if len(colormap) != len(test.entries) {
+			t.Errorf("%q: %d entries, want %d", test.encoded, len(colormap), len(test.entries))
+			continue
+		}
+		for i, entry := range colormap {
+			want := test.entries[i]
+			if entry.Seat != want.Seat || entry.Direction() != want.Direction() || entry.Fill.String() != want.Fill.String() {
+				t.Errorf("%q: entry %d is %s %d..%d, want %s %d..%d", test.encoded, i+1, entry, entry.Start, entry.End, want, want.Start, want.End)
+			}
+		}
+	}
+}
+
+func TestColormapError(t *testing.T) {
+	tests := []struct {
+		encoded  string
+		entry    int
+		position int
+		text     string
+	}{
+		{"kitchen=red", 1, 0, "kitchen=red"},
+		{"right=red;left=blurple", 2, 10, "left=blurple"},
+		{"right=red; top=gradient(red,blue);5..x=green", 3, 34, "5..x=green"},
+		{"0,41,ff,00,00-50,60,00", 2, 14, "50,60,00"},
+		{"0,41,ff,00,00-60,50,00,00,ff", 2, 14, "60,50,00,00,ff"},
+	}
+	for _, test := range tests {
+		_, err := ParseColormap(test.encoded)
+		colormapErr, ok := err.(*ColormapError)
+		if !ok {
+			t.Errorf("%q: error %v, want a ColormapError", test.encoded, err)
+			continue
+		}
+		if colormapErr.Entry != test.entry || colormapErr.Position != test.position || colormapErr.Text != test.text {
+			t.Errorf("%q: error at entry %d, position %d, text %q, want %d, %d, %q", test.encoded, colormapErr.Entry, colormapErr.Position, colormapErr.Text, test.entry, test.position, test.text)
+		}
+	}
+}
+
+func TestColormapString(t *testing.T) {
+	tests := []string{
+		"right=#ff0000",
+		"right=#ff0000;bottom=#00ff00;left=#0000ff;top=#ffffff",
+		"10..20=#123456;7=#abcdef",
+		"right=gradient(#ff0000,#0000ff)",
+		"top=gradient(hsv,mirror,#ff0000,#00ff00 25%,#0000ff)",
+		"left=gradient(oklab,#000000,#ffffff)",
+	}
+	for _, encoded := range tests {
+		colormap, err := ParseColormap(encoded)
+		if err != nil {
+			t.Errorf("%q: %v", encoded, err)
+			continue
+		}
+		if colormap.String() != encoded {
+			t.Errorf("%q serialized as %q", encoded, colormap.String())
+		}
+		again, err := ParseColormap(colormap.String())
+		if err != nil || again.String() != encoded {
+			t.Errorf("%q: parsing the serialized colormap gives %q, error %v", encoded, again.String(), err)
+		}
+	}
+	// the legacy format is serialized in the current one
+	colormap, err := ParseColormap("0,40,ff,00,00-50,60,00,00,ff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if colormap.String() != "right=#ff0000;50..60=#0000ff" {
+		t.Errorf("legacy colormap serialized as %q", colormap.String())
+	}
+}
+
+func TestApplyColormapInvalidRange(t *testing.T) {
+	leds := NewLeds(NewFakeOutput(), DefaultLedCount)
+	playTable := NewAnimationPlayTable(leds.GetFrameBuffer(), DefaultLayout)
+	colormap := Colormap{{"right", 0, 40, SolidFill(Color{255, 0, 0})}, {"", 20, 10, SolidFill(Color{0, 0, 255})}}
+	if err := playTable.ApplyColormap(colormap); err == nil {
+		t.Fatal("expected an error applying an invalid range")
+	}
+	if applied := playTable.GetColormap().String(); applied != "" {
+		t.Errorf("colormap %q applied in part", applied)
+	}
+}
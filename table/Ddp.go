@@ -0,0 +1,78 @@
+package table
+
+// DdpDefaultPort is the UDP port of the Distributed Display Protocol.
+const DdpDefaultPort = 4048
+
+const ddpVersion1 = 0x40
+const ddpPush = 0x01
+const ddpTypeRgb24 = 0x0b
+const ddpDefaultDevice = 0x01
+const ddpMaxData = 1440
+
+// Ddp sends frames using the Distributed Display Protocol, supported by
+// WLED, ESPixelStick, xLights and others.
+type Ddp struct {
+	udpOutput
+	sequence byte
+}
+
+// NewDdp creates a DDP output.
+func NewDdp(host string, port int) (*Ddp, error) {
+	if port == 0 {
+		port = DdpDefaultPort
+	}
+	ddp := new(Ddp)
+	ddp.host = host
+	ddp.port = port
+	err := ddp.Connect()
+	if err != nil {
+		return nil, err
+	}
+	return ddp, nil
+}
+
+// String returns the address of the controller.
+func (d *Ddp) String() string {
+	return "ddp " + d.address()
+}
+
+// StartStream does nothing, DDP controllers show frames as they arrive.
+func (d *Ddp) StartStream() error {
+	return nil
+}
+
+// SendFrame sends the frame, the last packet asks the controller to
+// show it.
+func (d *Ddp) SendFrame(frame []byte) error {
+	d.sequence = d.sequence%15 + 1
+	for offset := 0; offset < len(frame); offset += ddpMaxData {
+		end := offset + ddpMaxData
+		flags := byte(ddpVersion1)
+		if end >= len(frame) {
+			end = len(frame)
+			flags |= ddpPush
+		}
+		length := end - offset
+		packet := []byte{
+			flags, d.sequence, ddpTypeRgb24, ddpDefaultDevice,
+			byte(offset >> 24), byte(offset >> 16), byte(offset >> 8), byte(offset),
+			byte(length >> 8), byte(length),
+		}
+		packet = append(packet, frame[offset:end]...)
+		err := d.send(packet)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SupportsBrightness returns false, DDP has no brightness setting.
+func (d *Ddp) SupportsBrightness() bool {
+	return false
+}
+
+// SetBrightness is not supported by DDP.
+func (d *Ddp) SetBrightness(value byte) error {
+	return ErrBrightnessUnsupported
+}
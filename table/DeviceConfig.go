@@ -0,0 +1,175 @@
+package table
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+)
+
+const cmdSetChipType = 0x1c
+const cmdSetDotsPerSegment = 0x2d
+const cmdSetSegments = 0x2e
+const cmdSetColorOrder = 0x3c
+
+// ErrConfigUnsupported is returned for the device name and the station
+// Wi-Fi, their SP108E commands are not known yet and the vendor app is
+// still needed for them.
+var ErrConfigUnsupported = errors.New("setting not supported by the sp108e driver, use the vendor app")
+
+// ChipTypes lists the LED chip types of the SP108E in the order of their
+// codes.
+var ChipTypes = []string{
+	"SM16703", "TM1804", "UCS1903", "WS2811", "WS2801", "SK6812", "LPD6803",
+	"LPD8806", "APA102", "APA105", "DMX512", "TM1914", "TM1913", "P9813",
+	"INK1003", "P943S", "P9411", "P9413", "TX1812", "TX1813", "GS8206",
+	"GS8208", "SK9822", "TM1814", "SK6812_RGBW", "P9414", "PG412",
+}
+
+// ColorOrders lists the color orders of the SP108E in the order of their
+// codes.
+var ColorOrders = []string{"RGB", "RBG", "GRB", "GBR", "BRG", "BGR"}
+
+// WifiConfig holds station Wi-Fi credentials.
+type WifiConfig struct {
+	SSID     string `json:"ssid"`
+	Password string `json:"password"`
+}
+
+// DeviceConfig is a controller configuration file. Unset values are left
+// unchanged. Leds is the number of LEDs per segment.
+type DeviceConfig struct {
+	Name       string      `json:"name,omitempty"`
+	Chip       string      `json:"chip,omitempty"`
+	ColorOrder string      `json:"colororder,omitempty"`
+	Leds       int         `json:"leds,omitempty"`
+	Segments   int         `json:"segments,omitempty"`
+	Wifi       *WifiConfig `json:"wifi,omitempty"`
+}
+
+// LoadDeviceConfig reads and checks a controller configuration file.
+func LoadDeviceConfig(path string) (DeviceConfig, error) {
+	config := DeviceConfig{}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return config, fmt.Errorf("invalid device file %s: %v", path, err)
+	}
+	if config.Name != "" {
+		return config, fmt.Errorf("device name: %w", ErrConfigUnsupported)
+	}
+	if config.Wifi != nil {
+		return config, fmt.Errorf("wifi: %w", ErrConfigUnsupported)
+	}
+	if config.Chip != "" {
+		if _, err := codeOf(ChipTypes, config.Chip); err != nil {
+			return config, err
+		}
+	}
+	if config.ColorOrder != "" {
+		if _, err := codeOf(ColorOrders, config.ColorOrder); err != nil {
+			return config, err
+		}
+	}
+	if config.Leds < 0 || config.Leds > 2048 {
+		return config, fmt.Errorf("invalid number of leds %d, 1 to 2048 per segment", config.Leds)
+	}
+	if config.Segments < 0 || config.Segments > 32 {
+		return config, fmt.Errorf("invalid number of segments %d, 1 to 32", config.Segments)
+	}
+	return config, nil
+}
+
+func codeOf(names []string, name string) (byte, error) {
+	for i, n := range names {
+		if strings.EqualFold(n, name) {
+			return byte(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown value %q, supported are %s", name, strings.Join(names, ", "))
+}
+
+// Configure applies the configuration to the controller. Settings that
+// cannot be applied are rejected before anything is sent.
+func (leds *Sp108e) Configure(config DeviceConfig) error {
+	if config.Name != "" {
+		return fmt.Errorf("device name: %w", ErrConfigUnsupported)
+	}
+	if config.Wifi != nil {
+		return fmt.Errorf("wifi: %w", ErrConfigUnsupported)
+	}
+	if config.Chip != "" {
+		err := leds.SetChipType(config.Chip)
+		if err != nil {
+			return err
+		}
+	}
+	if config.ColorOrder != "" {
+		err := leds.SetColorOrder(config.ColorOrder)
+		if err != nil {
+			return err
+		}
+	}
+	if config.Leds > 0 || config.Segments > 0 {
+		return leds.SetLedCount(config.Leds, config.Segments)
+	}
+	return nil
+}
+
+// SetChipType sets the LED chip type, one of ChipTypes.
+func (leds *Sp108e) SetChipType(chip string) error {
+	code, err := codeOf(ChipTypes, chip)
+	if err != nil {
+		return err
+	}
+	return leds.sendConfigCommand(cmdSetChipType, []byte{code, 0x0, 0x0})
+}
+
+// SetColorOrder sets the color order of the LEDs, one of ColorOrders.
+func (leds *Sp108e) SetColorOrder(order string) error {
+	code, err := codeOf(ColorOrders, order)
+	if err != nil {
+		return err
+	}
+	return leds.sendConfigCommand(cmdSetColorOrder, []byte{code, 0x0, 0x0})
+}
+
+// SetLedCount sets the number of LEDs per segment and the number of
+// segments. A value of 0 leaves the setting unchanged.
+func (leds *Sp108e) SetLedCount(ledsPerSegment int, segments int) error {
+	if ledsPerSegment > 0 {
+		err := leds.sendConfigCommand(cmdSetDotsPerSegment, []byte{byte(ledsPerSegment), byte(ledsPerSegment >> 8), 0x0})
+		if err != nil {
+			return err
+		}
+	}
+	if segments > 0 {
+		return leds.sendConfigCommand(cmdSetSegments, []byte{byte(segments), 0x0, 0x0})
+	}
+	return nil
+}
+
+// GetName returns the device name.
+func (leds *Sp108e) GetName() (string, error) {
+	name, err := leds.query(cmdGetName, time.Second)
+	if err != nil {
+		return "", err
+	}
+	return strings.Trim(string(name), "\x00 "), nil
+}
+
+func (leds *Sp108e) sendConfigCommand(command byte, data []byte) error {
+	packet, err := leds.createCommandPacket(command, data)
+	if err != nil {
+		return err
+	}
+	err = leds.sendCommand(packet, false)
+	// the controller needs a moment to store the setting
+	time.Sleep(200 * time.Millisecond)
+	return err
+}
@@ -0,0 +1,66 @@
+package table
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadDeviceConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		valid       bool
+		unsupported bool
+	}{
+		{"full", `{"chip": "WS2811", "colororder": "GRB", "leds": 300, "segments": 1}`, true, false},
+		{"case insensitive", `{"chip": "ws2811", "colororder": "grb"}`, true, false},
+		{"empty", `{}`, true, false},
+		{"unknown chip", `{"chip": "WS9999"}`, false, false},
+		{"unknown color order", `{"colororder": "RGBW"}`, false, false},
+		{"too many leds", `{"leds": 2049}`, false, false},
+		{"negative leds", `{"leds": -1}`, false, false},
+		{"too many segments", `{"segments": 33}`, false, false},
+		{"invalid json", `{"chip": `, false, false},
+		{"device name", `{"name": "table"}`, false, true},
+		{"wifi", `{"wifi": {"ssid": "home", "password": "secret"}}`, false, true},
+	}
+	dir := t.TempDir()
+	for _, test := range tests {
+		path := filepath.Join(dir, "device.json")
+		err := ioutil.WriteFile(path, []byte(test.content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = LoadDeviceConfig(path)
+		if (err == nil) != test.valid {
+			t.Errorf("%s: error %v, want valid %v", test.name, err, test.valid)
+		}
+		if err != nil && test.unsupported != errors.Is(err, ErrConfigUnsupported) {
+			t.Errorf("%s: error %v, want unsupported %v", test.name, err, test.unsupported)
+		}
+	}
+}
+
+func TestCodeOf(t *testing.T) {
+	tests := []struct {
+		names []string
+		name  string
+		code  byte
+		valid bool
+	}{
+		{ChipTypes, "SM16703", 0, true},
+		{ChipTypes, "WS2811", 3, true},
+		{ChipTypes, "sk6812_rgbw", 24, true},
+		{ColorOrders, "GRB", 2, true},
+		{ColorOrders, "BGR", 5, true},
+		{ColorOrders, "RGBW", 0, false},
+	}
+	for _, test := range tests {
+		code, err := codeOf(test.names, test.name)
+		if (err == nil) != test.valid || code != test.code {
+			t.Errorf("%s: code %d, error %v, want %d, valid %v", test.name, code, err, test.code, test.valid)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package table
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const discoveryParallel = 128
+
+// DiscoveredController is a controller found on the local network.
+// Verified is true if it answered the SP108E status query, otherwise only
+// the port was open.
+type DiscoveredController struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Name     string `json:"name,omitempty"`
+	Verified bool   `json:"verified"`
+}
+
+// DiscoverSp108e probes the controller port on all hosts of the local IPv4
+// networks, at most the /24 around the own address for larger networks.
+func DiscoverSp108e(port int, timeout time.Duration) ([]DiscoveredController, error) {
+	hosts, err := localHosts()
+	if err != nil {
+		return nil, err
+	}
+	found := []DiscoveredController{}
+	var lock sync.Mutex
+	var wait sync.WaitGroup
+	slots := make(chan struct{}, discoveryParallel)
+	for _, host := range hosts {
+		wait.Add(1)
+		slots <- struct{}{}
+		go func(host string) {
+			defer wait.Done()
+			defer func() { <-slots }()
+			controller, ok := probeSp108e(host, port, timeout)
+			if ok {
+				lock.Lock()
+				found = append(found, controller)
+				lock.Unlock()
+			}
+		}(host)
+	}
+	wait.Wait()
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Verified != found[j].Verified {
+			return found[i].Verified
+		}
+		return bytes.Compare(net.ParseIP(found[i].Host).To4(), net.ParseIP(found[j].Host).To4()) < 0
+	})
+	return found, nil
+}
+
+// AutoSelectSp108e discovers the controllers and returns the host of the
+// first verified one.
+func AutoSelectSp108e(port int) (string, error) {
+	fmt.Println("discovering sp108e controllers")
+	found, err := DiscoverSp108e(port, time.Second)
+	if err != nil {
+		return "", err
+	}
+	for _, controller := range found {
+		if controller.Verified {
+			fmt.Println("selected controller", controller.Host, controller.Name)
+			return controller.Host, nil
+		}
+	}
+	return "", errors.New("no sp108e controller found")
+}
+
+func probeSp108e(host string, port int, timeout time.Duration) (DiscoveredController, bool) {
+	controller := DiscoveredController{Host: host, Port: port}
+	connection, err := net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(port)), timeout)
+	if err != nil {
+		return controller, false
+	}
+	defer connection.Close()
+	device := &Sp108e{ip: host, port: port, connection: connection}
+	status, err := device.query(cmdGetStatus, timeout)
+	if err == nil && isSp108eStatus(status) {
+		controller.Verified = true
+		name, err := device.query(cmdGetName, timeout)
+		if err == nil {
+			controller.Name = string(bytes.Trim(name, "\x00 "))
+		}
+	}
+	return controller, true
+}
+
+// isSp108eStatus returns true if the reply to the status query is framed
+// like an SP108E answer.
+func isSp108eStatus(status []byte) bool {
+	return len(status) > 1 && status[0] == cmdFrameStart && status[len(status)-1] == cmdFrameEnd
+}
+
+// localHosts returns the addresses of the other hosts in the local networks.
+func localHosts() ([]string, error) {
+	addresses, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	hosts := []string{}
+	for _, address := range addresses {
+		network, ok := address.(*net.IPNet)
+		if !ok || network.IP.IsLoopback() || network.IP.To4() == nil {
+			continue
+		}
+		own := network.IP.To4()
+		mask := network.Mask
+		if ones, _ := mask.Size(); ones < 24 {
+			mask = net.CIDRMask(24, 32)
+		}
+		base := own.Mask(mask)
+		ones, bits := mask.Size()
+		count := 1 << uint(bits-ones)
+		// skip the network and broadcast addresses
+		for i := 1; i < count-1; i++ {
+			ip := make(net.IP, 4)
+			value := uint32(base[0])<<24 | uint32(base[1])<<16 | uint32(base[2])<<8 | uint32(base[3])
+			value += uint32(i)
+			ip[0], ip[1], ip[2], ip[3] = byte(value>>24), byte(value>>16), byte(value>>8), byte(value)
+			if ip.Equal(own) || seen[ip.String()] {
+				continue
+			}
+			seen[ip.String()] = true
+			hosts = append(hosts, ip.String())
+		}
+	}
+	if len(hosts) == 0 {
+		return nil, errors.New("no local ipv4 network found")
+	}
+	return hosts, nil
+}
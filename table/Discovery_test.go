@@ -0,0 +1,86 @@
+package table
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestIsSp108eStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		status []byte
+		valid  bool
+	}{
+		{"status", []byte{0x38, 0x01, 0xcd, 0x0a, 0xff, 0x00, 0x2c, 0x01, 0x83}, true},
+		{"shortest", []byte{0x38, 0x83}, true},
+		{"empty", []byte{}, false},
+		{"single byte", []byte{0x38}, false},
+		{"confirmation", []byte{0x31}, false},
+		{"no frame end", []byte{0x38, 0x01, 0x02}, false},
+		{"no frame start", []byte{0x01, 0x02, 0x83}, false},
+		{"http", []byte("HTTP/1.1 400 Bad Request\r\n"), false},
+	}
+	for _, test := range tests {
+		if valid := isSp108eStatus(test.status); valid != test.valid {
+			t.Errorf("%s: valid %v, want %v", test.name, valid, test.valid)
+		}
+	}
+}
+
+// fakeDevice answers each command packet with the reply for its command.
+func fakeDevice(t *testing.T, replies map[byte][]byte) (string, int) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			connection, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer connection.Close()
+				packet := make([]byte, 6)
+				for {
+					n, err := connection.Read(packet)
+					if err != nil || n != 6 {
+						return
+					}
+					if reply, ok := replies[packet[4]]; ok {
+						connection.Write(reply)
+					}
+				}
+			}()
+		}
+	}()
+	address := listener.Addr().(*net.TCPAddr)
+	return address.IP.String(), address.Port
+}
+
+func TestProbeSp108e(t *testing.T) {
+	status := []byte{0x38, 0x01, 0xcd, 0x0a, 0xff, 0x00, 0x2c, 0x01, 0x83}
+	host, port := fakeDevice(t, map[byte][]byte{cmdGetStatus: status, cmdGetName: []byte("SP108E_6a1b\x00\x00")})
+	controller, ok := probeSp108e(host, port, time.Second)
+	if !ok || !controller.Verified || controller.Name != "SP108E_6a1b" {
+		t.Errorf("controller %+v, found %v, want the verified SP108E_6a1b", controller, ok)
+	}
+	// another device listening on the port
+	host, port = fakeDevice(t, map[byte][]byte{cmdGetStatus: []byte("HTTP/1.1 400 Bad Request\r\n")})
+	controller, ok = probeSp108e(host, port, time.Second)
+	if !ok || controller.Verified {
+		t.Errorf("controller %+v, found %v, want an unverified controller", controller, ok)
+	}
+	// nothing listening
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port = listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+	if _, ok = probeSp108e("127.0.0.1", port, time.Second); ok {
+		t.Error("found a controller on a closed port")
+	}
+}
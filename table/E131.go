@@ -0,0 +1,158 @@
+package table
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// E131DefaultPort is the UDP port of E1.31 (sACN).
+const E131DefaultPort = 5568
+
+// LEDs per DMX universe, 510 of the 512 channels
+const dmxUniverseLeds = 170
+
+const e131Priority = 100
+
+// E131 sends frames as E1.31 (sACN) data packets, one universe per 170
+// LEDs starting at the configured universe. Without a host, the packets
+// are sent to the multicast address of each universe.
+type E131 struct {
+	host     string
+	port     int
+	universe int
+	cid      []byte
+	sequence byte
+	outputs  map[int]*udpOutput
+}
+
+// NewE131 creates an E1.31 output.
+func NewE131(host string, port int, universe int) (*E131, error) {
+	if port == 0 {
+		port = E131DefaultPort
+	}
+	e131 := new(E131)
+	e131.host = host
+	e131.port = port
+	e131.universe = universe
+	e131.cid = make([]byte, 16)
+	rand.Read(e131.cid)
+	err := e131.Connect()
+	if err != nil {
+		return nil, err
+	}
+	return e131, nil
+}
+
+// String returns the address of the receiver.
+func (e *E131) String() string {
+	host := e.host
+	if host == "" {
+		host = "multicast"
+	}
+	return fmt.Sprintf("e131 %s:%d universe %d", host, e.port, e.universe)
+}
+
+// Connect creates the connection of the first universe, the others are
+// connected with the first frame that reaches them.
+func (e *E131) Connect() error {
+	e.Close()
+	e.outputs = map[int]*udpOutput{}
+	_, err := e.connectUniverse(e.universe)
+	return err
+}
+
+// connectUniverse creates the connection of a universe, one per universe
+// for multicast.
+func (e *E131) connectUniverse(universe int) (*udpOutput, error) {
+	host := e.host
+	if host == "" {
+		host = fmt.Sprintf("239.255.%d.%d", universe>>8, universe&0xff)
+	}
+	output := &udpOutput{host: host, port: e.port}
+	err := output.Connect()
+	if err != nil {
+		return nil, err
+	}
+	e.outputs[universe] = output
+	return output, nil
+}
+
+// Close closes the connections.
+func (e *E131) Close() error {
+	for _, output := range e.outputs {
+		output.Close()
+	}
+	e.outputs = nil
+	return nil
+}
+
+// StartStream does nothing, receivers show frames as they arrive.
+func (e *E131) StartStream() error {
+	return nil
+}
+
+// SendFrame sends one data packet per universe.
+func (e *E131) SendFrame(frame []byte) error {
+	e.sequence++
+	for i := 0; i*dmxUniverseLeds*3 < len(frame); i++ {
+		start := i * dmxUniverseLeds * 3
+		end := start + dmxUniverseLeds*3
+		if end > len(frame) {
+			end = len(frame)
+		}
+		universe := e.universe + i
+		output, ok := e.outputs[universe]
+		if !ok {
+			if e.outputs == nil {
+				return fmt.Errorf("no connection for universe %d", universe)
+			}
+			var err error
+			output, err = e.connectUniverse(universe)
+			if err != nil {
+				return err
+			}
+		}
+		err := output.send(e.packet(universe, frame[start:end]))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *E131) packet(universe int, data []byte) []byte {
+	length := 126 + len(data)
+	packet := make([]byte, 0, length)
+	// root layer
+	packet = append(packet, 0x00, 0x10, 0x00, 0x00)
+	packet = append(packet, "ASC-E1.17\x00\x00\x00"...)
+	packet = appendFlagsLength(packet, length-16)
+	packet = append(packet, 0x00, 0x00, 0x00, 0x04)
+	packet = append(packet, e.cid...)
+	// framing layer
+	packet = appendFlagsLength(packet, length-38)
+	packet = append(packet, 0x00, 0x00, 0x00, 0x02)
+	name := make([]byte, 64)
+	copy(name, "boardgametable")
+	packet = append(packet, name...)
+	packet = append(packet, e131Priority, 0x00, 0x00, e.sequence, 0x00, byte(universe>>8), byte(universe))
+	// dmp layer
+	packet = appendFlagsLength(packet, length-115)
+	count := len(data) + 1
+	packet = append(packet, 0x02, 0xa1, 0x00, 0x00, 0x00, 0x01, byte(count>>8), byte(count), 0x00)
+	return append(packet, data...)
+}
+
+func appendFlagsLength(packet []byte, length int) []byte {
+	return append(packet, 0x70|byte(length>>8), byte(length))
+}
+
+// SupportsBrightness returns false, E1.31 has no brightness setting.
+func (e *E131) SupportsBrightness() bool {
+	return false
+}
+
+// SetBrightness is not supported by E1.31.
+func (e *E131) SetBrightness(value byte) error {
+	return ErrBrightnessUnsupported
+}
@@ -0,0 +1,176 @@
+package table
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Effect is a transient effect rendered on top of the current animation.
+type Effect interface {
+	// Render draws the next step of the effect into the frame buffer and
+	// returns false once the effect has finished.
+	Render(frameBuffer []byte) bool
+}
+
+// FinishingEffect is an Effect that is told when it ends, either because it
+// has finished or because another effect replaced it. Finish is called
+// from outside the effect lock.
+type FinishingEffect interface {
+	Effect
+	Finish()
+}
+
+const shimmerSteps = 120
+const flashSteps = 12
+const sweepSteps = 200
+const sweepWidth = 40
+const attackWidth = 6
+const attackPixelsPerStep = 2
+
+// shimmerEffect sparkles random pixels, like dice rolling over the table.
+type shimmerEffect struct {
+	color Color
+	step  int
+}
+
+// NewShimmerEffect creates a dice roll shimmer in the given color.
+func NewShimmerEffect(color Color) Effect {
+	return &shimmerEffect{color: color}
+}
+
+// Render implements Effect.
+func (e *shimmerEffect) Render(frameBuffer []byte) bool {
+	pixels := len(frameBuffer) / 3
+	// fade the sparkle density out towards the end
+	density := 0.3 * float64(shimmerSteps-e.step) / shimmerSteps
+	for i := 0; i < pixels; i++ {
+		if rand.Float64() < density {
+			blendPixel(frameBuffer, i, e.color, 1)
+		}
+	}
+	e.step++
+	return e.step < shimmerSteps
+}
+
+// flashEffect flashes the whole table or a single seat a number of times.
+type flashEffect struct {
+	color   Color
+	flashes int
+	area    *Direction
+	step    int
+}
+
+// NewFlashEffect creates a flash effect, e.g. red for a critical hit.
+func NewFlashEffect(color Color, flashes int) Effect {
+	if flashes < 1 {
+		flashes = 1
+	}
+	return &flashEffect{color: color, flashes: flashes}
+}
+
+// NewSeatFlashEffect creates a flash effect on a single seat.
+func NewSeatFlashEffect(direction Direction, color Color, flashes int) Effect {
+	if flashes < 1 {
+		flashes = 1
+	}
+	return &flashEffect{color: color, flashes: flashes, area: &direction}
+}
+
+// Render implements Effect.
+func (e *flashEffect) Render(frameBuffer []byte) bool {
+	area := Direction{0, len(frameBuffer) / 3}
+	if e.area != nil {
+		area = *e.area
+	}
+	if e.step%flashSteps < flashSteps/2 {
+		for i := area.start; i < area.end; i++ {
+			blendPixel(frameBuffer, i, e.color, 1)
+		}
+	}
+	e.step++
+	return e.step < e.flashes*flashSteps
+}
+
+// sweepEffect sweeps a rainbow around the table.
+type sweepEffect struct {
+	step int
+}
+
+// NewSweepEffect creates a victory rainbow sweep.
+func NewSweepEffect() Effect {
+	return &sweepEffect{}
+}
+
+// Render implements Effect.
+func (e *sweepEffect) Render(frameBuffer []byte) bool {
+	pixels := len(frameBuffer) / 3
+	head := e.step * (pixels + sweepWidth) / sweepSteps
+	for i := 0; i < pixels; i++ {
+		hue := math.Mod(float64(i)*720/float64(pixels)+float64(e.step)*5, 360)
+		// the rainbow stays behind the head, so it covers the table once
+		if i < head {
+			blendPixel(frameBuffer, i, hsvToColor(hue, 1, 1), 1)
+		}
+	}
+	e.step++
+	return e.step < sweepSteps
+}
+
+// attackEffect moves a pulse from one seat to another along the shorter way
+// around the table and flashes the target seat on impact.
+type attackEffect struct {
+	color    Color
+	from     int
+	distance int
+	target   Direction
+	step     int
+}
+
+// NewAttackEffect creates a pulse travelling from one seat to another.
+func NewAttackEffect(from Direction, to Direction, color Color) Effect {
+	return &attackEffect{
+		color:  color,
+		from:   (from.start + from.end) / 2,
+		target: to,
+		// the distance is resolved on the first frame when the strip length is known
+		distance: (to.start+to.end)/2 - (from.start+from.end)/2,
+	}
+}
+
+// Render implements Effect.
+func (e *attackEffect) Render(frameBuffer []byte) bool {
+	pixels := len(frameBuffer) / 3
+	if pixels == 0 {
+		return false
+	}
+	if e.step == 0 {
+		// take the shorter way around the table
+		if e.distance > pixels/2 {
+			e.distance -= pixels
+		} else if e.distance < -pixels/2 {
+			e.distance += pixels
+		}
+	}
+	travelled := e.step * attackPixelsPerStep
+	length := int(math.Abs(float64(e.distance)))
+	if travelled < length {
+		sign := 1
+		if e.distance < 0 {
+			sign = -1
+		}
+		for i := 0; i < attackWidth; i++ {
+			position := ((e.from+sign*(travelled-i))%pixels + pixels) % pixels
+			blendPixel(frameBuffer, position, e.color, 1-float64(i)/attackWidth)
+		}
+	} else {
+		impact := (travelled - length) / attackPixelsPerStep
+		if impact >= flashSteps {
+			return false
+		}
+		for i := e.target.start; i < e.target.end; i++ {
+			blendPixel(frameBuffer, i, e.color, 1-float64(impact)/flashSteps)
+		}
+	}
+	e.step++
+	return true
+}
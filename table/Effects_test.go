@@ -0,0 +1,103 @@
+package table
+
+import (
+	"testing"
+)
+
+// renderFrames renders an effect until it finishes, each frame on a cleared
+// buffer, and returns the frames.
+func renderFrames(effect Effect, pixels int) [][]byte {
+	frames := [][]byte{}
+	for len(frames) < 1000 {
+		frame := make([]byte, pixels*3)
+		running := effect.Render(frame)
+		if !running {
+			break
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+func TestEffectDurations(t *testing.T) {
+	tests := []struct {
+		name   string
+		effect Effect
+		frames int
+	}{
+		{"shimmer", NewShimmerEffect(Colors["white"]), shimmerSteps - 1},
+		{"flash", NewFlashEffect(Colors["red"], 3), 3*flashSteps - 1},
+		{"flash without count", NewFlashEffect(Colors["red"], 0), flashSteps - 1},
+		{"sweep", NewSweepEffect(), sweepSteps - 1},
+		// 120 pixels the short way at two pixels per step, then the impact
+		{"attack", NewAttackEffect(Directions["right"], Directions["top"], Colors["red"]), 60 + flashSteps},
+	}
+	for _, test := range tests {
+		if frames := len(renderFrames(test.effect, DefaultLedCount)); frames != test.frames {
+			t.Errorf("%s: %d running frames, want %d", test.name, frames, test.frames)
+		}
+	}
+}
+
+func TestSeatFlashEffect(t *testing.T) {
+	red := Colors["red"]
+	frames := renderFrames(NewSeatFlashEffect(Directions["left"], red, 2), DefaultLedCount)
+	tests := []struct {
+		frame int
+		index int
+		color Color
+	}{
+		{0, Directions["left"].start, red},
+		{0, Directions["left"].end - 1, red},
+		{0, Directions["left"].end, Color{}},
+		{0, Directions["right"].start, Color{}},
+		{flashSteps / 2, Directions["left"].start, Color{}},
+		{flashSteps, Directions["left"].start, red},
+	}
+	for _, test := range tests {
+		if pixel := pixelAt(&frames[test.frame], test.index); pixel != test.color {
+			t.Errorf("frame %d: pixel %d is %s, want %s", test.frame, test.index, pixel.Hex(), test.color.Hex())
+		}
+	}
+}
+
+func TestSweepEffect(t *testing.T) {
+	frames := renderFrames(NewSweepEffect(), DefaultLedCount)
+	if pixel := pixelAt(&frames[0], 0); pixel != (Color{}) {
+		t.Errorf("first frame pixel %s, want the sweep not started", pixel.Hex())
+	}
+	last := frames[len(frames)-1]
+	for i := 0; i < DefaultLedCount; i++ {
+		if pixelAt(&last, i) == (Color{}) {
+			t.Fatalf("pixel %d is off in the last frame, want the table covered", i)
+		}
+	}
+}
+
+func TestAttackEffect(t *testing.T) {
+	red := Colors["red"]
+	// from the middle of the right seat (20) to the middle of the top
+	// seat (200), the shorter way leads backwards over the strip start
+	frames := renderFrames(NewAttackEffect(Directions["right"], Directions["top"], red), DefaultLedCount)
+	tests := []struct {
+		frame int
+		index int
+		color Color
+	}{
+		{0, 20, red},
+		{1, 18, red},
+		{1, 30, Color{}},
+		{11, 298, red},
+		{60, Directions["top"].start, red},
+		{60, Directions["right"].start, Color{}},
+	}
+	for _, test := range tests {
+		if pixel := pixelAt(&frames[test.frame], test.index); pixel != test.color {
+			t.Errorf("frame %d: pixel %d is %s, want %s", test.frame, test.index, pixel.Hex(), test.color.Hex())
+		}
+	}
+	// the impact fades out on the target seat
+	if pixel := pixelAt(&frames[len(frames)-1], Directions["top"].start); pixel.r == 0 || pixel.r >= red.r {
+		t.Errorf("last impact pixel %s, want a faded red", pixel.Hex())
+	}
+}
@@ -0,0 +1,140 @@
+package table
+
+import (
+	"sync"
+	"time"
+)
+
+// Event types.
+const (
+	EventTurnChanged            = "turn_changed"
+	EventSeatColorChanged       = "seat_color_changed"
+	EventSessionStarted         = "session_started"
+	EventSessionEnded           = "session_ended"
+	EventControllerDisconnected = "controller_disconnected"
+	EventControllerReconnected  = "controller_reconnected"
+)
+
+const eventWatchInterval = 500 * time.Millisecond
+
+// Event describes something that happened on the table.
+type Event struct {
+	Type  string                 `json:"event"`
+	Table string                 `json:"table,omitempty"`
+	Time  time.Time              `json:"time"`
+	Data  map[string]interface{} `json:"data,omitempty"`
+}
+
+// EventListener receives events.
+type EventListener func(event Event)
+
+// NewEvent creates an event happening now.
+func NewEvent(eventType string, data map[string]interface{}) Event {
+	return Event{Type: eventType, Time: time.Now(), Data: data}
+}
+
+// EventWatcher watches the controller and its play table animation and
+// emits events when the turn, the seat colors or the session change. The
+// state has to be stable for two checks, so the short gap while an
+// animation is replaced does not end a session.
+type EventWatcher struct {
+	leds       *Leds
+	tableID    string
+	listeners  []EventListener
+	lock       sync.Mutex
+	session    bool
+	active     []string
+	seatColors map[string]string
+	pending    bool
+}
+
+// NewEventWatcher creates and starts an event watcher. The events carry
+// tableID unless it is empty.
+func NewEventWatcher(leds *Leds, tableID string) *EventWatcher {
+	watcher := new(EventWatcher)
+	watcher.leds = leds
+	watcher.tableID = tableID
+	watcher.active = []string{}
+	watcher.seatColors = map[string]string{}
+	leds.AddEventListener(watcher.emit)
+	go func() {
+		for {
+			time.Sleep(eventWatchInterval)
+			watcher.check()
+		}
+	}()
+	return watcher
+}
+
+// AddListener adds a listener for all events.
+func (ew *EventWatcher) AddListener(listener EventListener) {
+	ew.lock.Lock()
+	defer ew.lock.Unlock()
+	ew.listeners = append(ew.listeners, listener)
+}
+
+func (ew *EventWatcher) emit(event Event) {
+	event.Table = ew.tableID
+	ew.lock.Lock()
+	listeners := append([]EventListener{}, ew.listeners...)
+	ew.lock.Unlock()
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
+
+func (ew *EventWatcher) check() {
+	playTable, ok := ew.leds.GetCurrentAnimation().(*AnimationPlayTable)
+	session := ok && ew.leds.IsAnimationRunning()
+	if session != ew.session {
+		// wait for the next check to confirm the change
+		if !ew.pending {
+			ew.pending = true
+			return
+		}
+		ew.pending = false
+		ew.session = session
+		if session {
+			ew.emit(NewEvent(EventSessionStarted, nil))
+		} else {
+			ew.emit(NewEvent(EventSessionEnded, nil))
+			ew.active = []string{}
+			ew.seatColors = map[string]string{}
+		}
+	}
+	ew.pending = false
+	if !session {
+		return
+	}
+	for _, entry := range playTable.GetColormap() {
+		if entry.Seat == "" {
+			continue
+		}
+		fill := entry.Fill.String()
+		if ew.seatColors[entry.Seat] != fill {
+			ew.seatColors[entry.Seat] = fill
+			ew.emit(NewEvent(EventSeatColorChanged, map[string]interface{}{"seat": entry.Seat, "fill": fill}))
+		}
+	}
+	active := []string{}
+	for _, direction := range playTable.GetActiveDirections() {
+		active = append(active, playTable.GetLayout().SeatName(direction))
+	}
+	if !equalStrings(active, ew.active) {
+		previous := ew.active
+		ew.active = active
+		ew.emit(NewEvent(EventTurnChanged, map[string]interface{}{"active": active, "previous": previous}))
+	}
+}
+
+func equalStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
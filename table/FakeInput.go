@@ -0,0 +1,44 @@
+package table
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeInput is an input source without hardware, for tests and
+// simulations. Presses are injected with Press.
+type FakeInput struct {
+	presses chan InputPress
+	once    sync.Once
+}
+
+// NewFakeInput creates a fake input source.
+func NewFakeInput() *FakeInput {
+	input := new(FakeInput)
+	input.presses = make(chan InputPress, 16)
+	return input
+}
+
+// Press reports a press of the named input now.
+func (fi *FakeInput) Press(name string) {
+	fi.PressAt(name, time.Now())
+}
+
+// PressAt reports a press of the named input at the given time, so
+// debouncing can be checked without waiting.
+func (fi *FakeInput) PressAt(name string, at time.Time) {
+	fi.presses <- InputPress{name, at}
+}
+
+// Presses returns the channel of presses.
+func (fi *FakeInput) Presses() <-chan InputPress {
+	return fi.presses
+}
+
+// Close closes the presses channel.
+func (fi *FakeInput) Close() error {
+	fi.once.Do(func() {
+		close(fi.presses)
+	})
+	return nil
+}
@@ -0,0 +1,114 @@
+package table
+
+import (
+	"errors"
+	"sync"
+)
+
+// FakeOutput is an output without hardware, for tests and running the
+// server without a controller. It keeps the last frame and can simulate a
+// controller without brightness setting or a lost connection.
+type FakeOutput struct {
+	frame        []byte
+	frames       int
+	brightness   byte
+	noBrightness bool
+	failing      bool
+	streaming    bool
+	lock         sync.Mutex
+}
+
+// NewFakeOutput creates a fake output.
+func NewFakeOutput() *FakeOutput {
+	output := new(FakeOutput)
+	output.brightness = 255
+	return output
+}
+
+// String describes the output.
+func (fo *FakeOutput) String() string {
+	return "fake"
+}
+
+// Connect does nothing.
+func (fo *FakeOutput) Connect() error {
+	return nil
+}
+
+// Close does nothing.
+func (fo *FakeOutput) Close() error {
+	return nil
+}
+
+// StartStream records that frames are shown.
+func (fo *FakeOutput) StartStream() error {
+	fo.lock.Lock()
+	defer fo.lock.Unlock()
+	fo.streaming = true
+	return nil
+}
+
+// SendFrame keeps a copy of the frame.
+func (fo *FakeOutput) SendFrame(frame []byte) error {
+	fo.lock.Lock()
+	defer fo.lock.Unlock()
+	if fo.failing {
+		return errors.New("fake output failing")
+	}
+	fo.frame = append(fo.frame[:0], frame...)
+	fo.frames++
+	return nil
+}
+
+// SupportsBrightness returns false if brightness setting is switched off.
+func (fo *FakeOutput) SupportsBrightness() bool {
+	fo.lock.Lock()
+	defer fo.lock.Unlock()
+	return !fo.noBrightness
+}
+
+// SetBrightness records the brightness.
+func (fo *FakeOutput) SetBrightness(value byte) error {
+	fo.lock.Lock()
+	defer fo.lock.Unlock()
+	if fo.noBrightness {
+		return ErrBrightnessUnsupported
+	}
+	fo.brightness = value
+	return nil
+}
+
+// GetFrame returns a copy of the last frame and the number of frames sent.
+func (fo *FakeOutput) GetFrame() ([]byte, int) {
+	fo.lock.Lock()
+	defer fo.lock.Unlock()
+	return append([]byte{}, fo.frame...), fo.frames
+}
+
+// GetBrightness returns the brightness last set.
+func (fo *FakeOutput) GetBrightness() byte {
+	fo.lock.Lock()
+	defer fo.lock.Unlock()
+	return fo.brightness
+}
+
+// IsStreaming returns true once StartStream was called.
+func (fo *FakeOutput) IsStreaming() bool {
+	fo.lock.Lock()
+	defer fo.lock.Unlock()
+	return fo.streaming
+}
+
+// SetBrightnessSupported selects whether SetBrightness is supported.
+func (fo *FakeOutput) SetBrightnessSupported(supported bool) {
+	fo.lock.Lock()
+	defer fo.lock.Unlock()
+	fo.noBrightness = !supported
+}
+
+// SetFailing makes SendFrame fail, like a controller that went away.
+func (fo *FakeOutput) SetFailing(failing bool) {
+	fo.lock.Lock()
+	defer fo.lock.Unlock()
+	fo.failing = failing
+}
@@ -0,0 +1,244 @@
+package table
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ColorSpace selects how gradients interpolate between their stops.
+type ColorSpace string
+
+// Supported color spaces for gradient interpolation.
+const (
+	ColorSpaceRGB   ColorSpace = "rgb"
+	ColorSpaceHSV   ColorSpace = "hsv"
+	ColorSpaceOKLab ColorSpace = "oklab"
+)
+
+// Fill describes how the pixels of a range are colored. A fill with a single
+// stop is a solid color, more stops form a linear gradient.
+type Fill struct {
+	stops     []Color
+	positions []float64
+	mirror    bool
+	space     ColorSpace
+}
+
+// SolidFill creates a fill with a single color.
+func SolidFill(color Color) Fill {
+	return Fill{stops: []Color{color}}
+}
+
+// GradientFill creates a linear gradient through evenly spaced colors.
+func GradientFill(stops ...Color) (Fill, error) {
+	return NewGradientFill(stops, nil, false, ColorSpaceRGB)
+}
+
+// NewGradientFill creates a linear gradient. Positions range from 0 to 1 and
+// may be nil for evenly spaced stops, negative positions are distributed
+// evenly between their neighbours. A mirrored gradient starts with the first
+// stop in the center of the range and ends with the last stop at both edges.
+func NewGradientFill(stops []Color, positions []float64, mirror bool, space ColorSpace) (Fill, error) {
+	if len(stops) < 2 {
+		return Fill{}, errors.New("gradient needs at least two colors")
+	}
+	if positions != nil && len(positions) != len(stops) {
+		return Fill{}, errors.New("gradient needs a position for every color")
+	}
+	switch space {
+	case "":
+		space = ColorSpaceRGB
+	case ColorSpaceRGB, ColorSpaceHSV, ColorSpaceOKLab:
+	default:
+		return Fill{}, fmt.Errorf("unknown color space %q", space)
+	}
+	resolved, err := resolvePositions(positions, len(stops))
+	if err != nil {
+		return Fill{}, err
+	}
+	return Fill{append([]Color{}, stops...), resolved, mirror, space}, nil
+}
+
+// resolvePositions fills in missing positions and checks they are ascending.
+func resolvePositions(positions []float64, count int) ([]float64, error) {
+	resolved := make([]float64, count)
+	for i := range resolved {
+		resolved[i] = -1
+		if positions != nil {
+			resolved[i] = positions[i]
+		}
+	}
+	if resolved[0] < 0 {
+		resolved[0] = 0
+	}
+	if resolved[count-1] < 0 {
+		resolved[count-1] = 1
+	}
+	last := 0
+	for i := 1; i < count; i++ {
+		if resolved[i] < 0 {
+			continue
+		}
+		if resolved[i] > 1 || resolved[i] < resolved[last] {
+			return nil, fmt.Errorf("gradient position %g%% out of order", resolved[i]*100)
+		}
+		for j := last + 1; j < i; j++ {
+			resolved[j] = resolved[last] + (resolved[i]-resolved[last])*float64(j-last)/float64(i-last)
+		}
+		last = i
+	}
+	return resolved, nil
+}
+
+// IsSolid returns true if the fill consists of a single color.
+func (f Fill) IsSolid() bool {
+	return len(f.stops) == 1
+}
+
+// Stops returns the colors of the fill.
+func (f Fill) Stops() []Color {
+	return append([]Color{}, f.stops...)
+}
+
+// colorAt returns the color of the pixel at position within a range of length pixels.
+func (f Fill) colorAt(position int, length int) Color {
+	if len(f.stops) == 0 {
+		return Color{}
+	}
+	if len(f.stops) == 1 || length < 2 {
+		return f.stops[0]
+	}
+	t := float64(position) / float64(length-1)
+	if f.mirror {
+		t = math.Abs(2*t - 1)
+	}
+	if t <= f.positions[0] {
+		return f.stops[0]
+	}
+	for i := 1; i < len(f.stops); i++ {
+		if t <= f.positions[i] {
+			span := f.positions[i] - f.positions[i-1]
+			if span <= 0 {
+				return f.stops[i]
+			}
+			return interpolateColors(f.stops[i-1], f.stops[i], (t-f.positions[i-1])/span, f.space)
+		}
+	}
+	return f.stops[len(f.stops)-1]
+}
+
+func interpolateColors(from Color, to Color, t float64, space ColorSpace) Color {
+	switch space {
+	case ColorSpaceHSV:
+		h1, s1, v1 := colorToHSV(from)
+		h2, s2, v2 := colorToHSV(to)
+		// take the shorter way around the hue circle
+		if h2-h1 > 180 {
+			h1 += 360
+		} else if h1-h2 > 180 {
+			h2 += 360
+		}
+		return hsvToColor(math.Mod(lerp(h1, h2, t), 360), lerp(s1, s2, t), lerp(v1, v2, t))
+	case ColorSpaceOKLab:
+		l1, a1, b1 := colorToOKLab(from)
+		l2, a2, b2 := colorToOKLab(to)
+		return okLabToColor(lerp(l1, l2, t), lerp(a1, a2, t), lerp(b1, b2, t))
+	default:
+		return mixColors(from, to, t)
+	}
+}
+
+func lerp(a float64, b float64, t float64) float64 {
+	return a + (b-a)*t
+}
+
+func mixColors(from Color, to Color, t float64) Color {
+	mix := func(a, b byte) byte {
+		return byte(float64(a) + (float64(b)-float64(a))*t + 0.5)
+	}
+	return Color{mix(from.r, to.r), mix(from.g, to.g), mix(from.b, to.b)}
+}
+
+func colorToHSV(c Color) (float64, float64, float64) {
+	r, g, b := float64(c.r)/255, float64(c.g)/255, float64(c.b)/255
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	delta := max - min
+	var h float64
+	switch {
+	case delta == 0:
+		h = 0
+	case max == r:
+		h = 60 * math.Mod((g-b)/delta, 6)
+	case max == g:
+		h = 60 * ((b-r)/delta + 2)
+	default:
+		h = 60 * ((r-g)/delta + 4)
+	}
+	if h < 0 {
+		h += 360
+	}
+	s := 0.0
+	if max > 0 {
+		s = delta / max
+	}
+	return h, s, max
+}
+
+func srgbToLinear(value byte) float64 {
+	c := float64(value) / 255
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(c float64) float64 {
+	if c <= 0.0031308 {
+		return 12.92 * c
+	}
+	return 1.055*math.Pow(c, 1/2.4) - 0.055
+}
+
+func colorToOKLab(c Color) (float64, float64, float64) {
+	r, g, b := srgbToLinear(c.r), srgbToLinear(c.g), srgbToLinear(c.b)
+	l := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*b)
+	m := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*b)
+	s := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*b)
+	return 0.2104542553*l + 0.7936177850*m - 0.0040720468*s,
+		1.9779984951*l - 2.4285922050*m + 0.4505937099*s,
+		0.0259040371*l + 0.7827717662*m - 0.8086757660*s
+}
+
+func okLabToColor(lightness float64, a float64, b float64) Color {
+	l := math.Pow(lightness+0.3963377774*a+0.2158037573*b, 3)
+	m := math.Pow(lightness-0.1055613458*a-0.0638541728*b, 3)
+	s := math.Pow(lightness-0.0894841775*a-1.2914855480*b, 3)
+	return colorFromFloats(
+		linearToSRGB(4.0767416621*l-3.3077115913*m+0.2309699292*s),
+		linearToSRGB(-1.2684380046*l+2.6097574011*m-0.3413193965*s),
+		linearToSRGB(-0.0041960863*l-0.7034186147*m+1.7076147010*s))
+}
+
+// paint writes the fill into the frame buffer for the given range.
+func (f Fill) paint(frameBuffer []byte, direction Direction) error {
+	length := direction.end - direction.start
+	for i := direction.start; i < direction.end; i++ {
+		if i*3+2 >= len(frameBuffer) {
+			return errors.New("frame pixel out of bounds")
+		}
+		color := f.colorAt(i-direction.start, length)
+		frameBuffer[i*3] = color.r
+		frameBuffer[i*3+1] = color.g
+		frameBuffer[i*3+2] = color.b
+	}
+	return nil
+}
+
+// dimRange scales the pixels of a range in the frame buffer by factor.
+func dimRange(frameBuffer []byte, direction Direction, factor float64) {
+	for i := direction.start * 3; i < direction.end*3 && i < len(frameBuffer); i++ {
+		frameBuffer[i] = byte(float64(frameBuffer[i]) * factor)
+	}
+}
@@ -0,0 +1,117 @@
+package table
+
+import (
+	"testing"
+)
+
+var (
+	testRed   = Color{255, 0, 0}
+	testGreen = Color{0, 255, 0}
+	testBlue  = Color{0, 0, 255}
+)
+
+func TestGradientEndpoints(t *testing.T) {
+	for _, space := range []ColorSpace{ColorSpaceRGB, ColorSpaceHSV, ColorSpaceOKLab} {
+		fill, err := NewGradientFill([]Color{testRed, testGreen, testBlue}, nil, false, space)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, length := range []int{2, 3, 10, 41} {
+			if color := fill.colorAt(0, length); color != testRed {
+				t.Errorf("%s, %d pixels: first pixel %s, want %s", space, length, color.Hex(), testRed.Hex())
+			}
+			if color := fill.colorAt(length-1, length); color != testBlue {
+				t.Errorf("%s, %d pixels: last pixel %s, want %s", space, length, color.Hex(), testBlue.Hex())
+			}
+		}
+		if color := fill.colorAt(5, 11); color != testGreen {
+			t.Errorf("%s: middle pixel %s, want %s", space, color.Hex(), testGreen.Hex())
+		}
+	}
+}
+
+func TestGradientPositions(t *testing.T) {
+	fill, err := NewGradientFill([]Color{testRed, testGreen, testBlue}, []float64{-1, 0.25, -1}, false, ColorSpaceRGB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		position int
+		color    Color
+	}{
+		{0, testRed},
+		{2, Color{128, 128, 0}},
+		{4, testGreen},
+		{10, Color{0, 128, 128}},
+		{16, testBlue},
+	}
+	for _, test := range tests {
+		if color := fill.colorAt(test.position, 17); color != test.color {
+			t.Errorf("pixel %d: %s, want %s", test.position, color.Hex(), test.color.Hex())
+		}
+	}
+	for _, positions := range [][]float64{{0, 0.6, 0.4}, {0, 1.2, 1}, {0, 1}} {
+		if _, err := NewGradientFill([]Color{testRed, testGreen, testBlue}, positions, false, ColorSpaceRGB); err == nil {
+			t.Errorf("positions %v: expected an error", positions)
+		}
+	}
+}
+
+func TestGradientMirror(t *testing.T) {
+	fill, err := NewGradientFill([]Color{testRed, testBlue}, nil, true, ColorSpaceRGB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame := make([]byte, 11*3)
+	err = fill.paint(frame, Direction{0, 11})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pixel := func(i int) Color {
+		return Color{frame[i*3], frame[i*3+1], frame[i*3+2]}
+	}
+	if pixel(5) != testRed {
+		t.Errorf("center pixel %s, want %s", pixel(5).Hex(), testRed.Hex())
+	}
+	if pixel(0) != testBlue || pixel(10) != testBlue {
+		t.Errorf("edge pixels %s and %s, want %s", pixel(0).Hex(), pixel(10).Hex(), testBlue.Hex())
+	}
+	for i := 0; i < 5; i++ {
+		if pixel(i) != pixel(10-i) {
+			t.Errorf("pixel %d is %s, its mirror %s", i, pixel(i).Hex(), pixel(10-i).Hex())
+		}
+	}
+}
+
+func TestGradientHueWrap(t *testing.T) {
+	tests := []struct {
+		from   string
+		to     string
+		middle Color
+	}{
+		// the short way around the hue circle passes red, not cyan
+		{"hsv(350,100,100)", "hsv(10,100,100)", testRed},
+		{"hsv(10,100,100)", "hsv(350,100,100)", testRed},
+		{"hsv(300,100,100)", "hsv(60,100,100)", testRed},
+		{"hsv(0,100,100)", "hsv(120,100,100)", Color{255, 255, 0}},
+	}
+	for _, test := range tests {
+		fill, err := ParseFill("gradient(hsv," + test.from + "," + test.to + ")")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if color := fill.colorAt(1, 3); color != test.middle {
+			t.Errorf("%s to %s: middle %s, want %s", test.from, test.to, color.Hex(), test.middle.Hex())
+		}
+	}
+}
+
+func TestFillPaintOutOfBounds(t *testing.T) {
+	frame := make([]byte, 10*3)
+	if err := SolidFill(testRed).paint(frame, Direction{5, 11}); err == nil {
+		t.Error("expected an error painting beyond the frame")
+	}
+	if err := SolidFill(testRed).paint(frame, Direction{5, 10}); err != nil {
+		t.Error(err)
+	}
+}
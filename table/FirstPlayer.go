@@ -0,0 +1,104 @@
+package table
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sync"
+)
+
+const spinTurns = 3
+const spinSteps = 300
+const spinHoldSteps = 60
+const spinTailWidth = 8
+
+// PickRandomSeat picks a random seat among the colored seats in the rotation.
+// Seats are weighted by the given weights, defaulting to 1; a weight of 0
+// excludes a seat.
+func (pt *AnimationPlayTable) PickRandomSeat(weights map[Direction]float64) (Direction, error) {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	candidates := []Direction{}
+	candidateWeights := []float64{}
+	total := 0.0
+	for _, direction := range pt.layout.SeatDirections() {
+		if _, ok := (*pt.playerDirections)[direction]; !ok || pt.seatState(direction) != SeatPlaying {
+			continue
+		}
+		weight, ok := weights[direction]
+		if !ok {
+			weight = 1
+		}
+		if weight < 0 {
+			return Direction{}, errors.New("negative seat weight")
+		}
+		if weight == 0 {
+			continue
+		}
+		candidates = append(candidates, direction)
+		candidateWeights = append(candidateWeights, weight)
+		total += weight
+	}
+	if len(candidates) == 0 {
+		return Direction{}, errors.New("no seat to pick from")
+	}
+	pick := rand.Float64() * total
+	for i, weight := range candidateWeights {
+		if pick < weight {
+			return candidates[i], nil
+		}
+		pick -= weight
+	}
+	return candidates[len(candidates)-1], nil
+}
+
+// spinEffect runs a light around the table that decelerates and stops on
+// the target seat, like spinning a bottle.
+type spinEffect struct {
+	color    Color
+	target   Direction
+	onFinish func()
+	once     sync.Once
+	step     int
+}
+
+// NewSpinEffect creates a spin the bottle effect stopping on target. onFinish
+// is called once the effect has finished or has been replaced by another
+// effect, so the result is applied in any case.
+func NewSpinEffect(target Direction, color Color, onFinish func()) Effect {
+	return &spinEffect{color: color, target: target, onFinish: onFinish}
+}
+
+// Finish implements FinishingEffect.
+func (e *spinEffect) Finish() {
+	e.once.Do(func() {
+		if e.onFinish != nil {
+			e.onFinish()
+		}
+	})
+}
+
+// Render implements Effect.
+func (e *spinEffect) Render(frameBuffer []byte) bool {
+	pixels := len(frameBuffer) / 3
+	if pixels == 0 || e.step >= spinSteps+spinHoldSteps {
+		return false
+	}
+	// darken the table so the light stands out
+	dimRange(frameBuffer, Direction{0, pixels}, 0.2)
+	if e.step < spinSteps {
+		// ease out: fast at the start, slowing down towards the target
+		t := float64(e.step) / spinSteps
+		distance := float64(spinTurns*pixels + (e.target.start+e.target.end)/2)
+		head := int(distance * (1 - math.Pow(1-t, 3)))
+		for i := 0; i < spinTailWidth; i++ {
+			blendPixel(frameBuffer, ((head-i)%pixels+pixels)%pixels, e.color, 1-float64(i)/spinTailWidth)
+		}
+	} else {
+		for i := e.target.start; i < e.target.end; i++ {
+			blendPixel(frameBuffer, i, e.color, 1)
+		}
+	}
+	e.step++
+	return true
+}
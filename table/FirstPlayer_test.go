@@ -0,0 +1,85 @@
+package table
+
+import (
+	"testing"
+)
+
+func TestPickRandomSeat(t *testing.T) {
+	leds := NewLeds(NewFakeOutput(), DefaultLedCount)
+	playTable := NewAnimationPlayTable(leds.GetFrameBuffer(), DefaultLayout)
+	if _, err := playTable.PickRandomSeat(nil); err == nil {
+		t.Error("expected an error without colored seats")
+	}
+	for _, name := range []string{"right", "bottom", "left"} {
+		err := playTable.SetPlayerColor(Directions[name], Colors["blue"])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := playTable.SetSeatState(Directions["left"], SeatPassed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the top seat has no color and left is out of the rotation
+	for i := 0; i < 50; i++ {
+		seat, err := playTable.PickRandomSeat(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seat != Directions["right"] && seat != Directions["bottom"] {
+			t.Fatalf("picked %d..%d, want right or bottom", seat.start, seat.end)
+		}
+	}
+	weights := map[Direction]float64{Directions["right"]: 0, Directions["bottom"]: 2}
+	for i := 0; i < 50; i++ {
+		seat, err := playTable.PickRandomSeat(weights)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seat != Directions["bottom"] {
+			t.Fatalf("picked %d..%d, want the only weighted seat", seat.start, seat.end)
+		}
+	}
+	weights[Directions["bottom"]] = 0
+	if _, err = playTable.PickRandomSeat(weights); err == nil {
+		t.Error("expected an error with all weights 0")
+	}
+	weights[Directions["bottom"]] = -1
+	if _, err = playTable.PickRandomSeat(weights); err == nil {
+		t.Error("expected an error with a negative weight")
+	}
+}
+
+func TestSpinEffect(t *testing.T) {
+	finished := 0
+	target := Directions["left"]
+	effect := NewSpinEffect(target, Colors["white"], func() { finished++ })
+	frames := renderFrames(effect, DefaultLedCount)
+	if len(frames) != spinSteps+spinHoldSteps {
+		t.Errorf("%d running frames, want %d", len(frames), spinSteps+spinHoldSteps)
+	}
+	// the light slows down to the middle of the target seat
+	head := -1
+	for i := 0; i < DefaultLedCount; i++ {
+		if pixelAt(&frames[spinSteps-1], i) == Colors["white"] {
+			head = i
+		}
+	}
+	if middle := (target.start + target.end) / 2; head < middle-1 || head > middle {
+		t.Errorf("last spin frame: head at pixel %d, want %d", head, middle)
+	}
+	last := frames[len(frames)-1]
+	if pixel := pixelAt(&last, target.start); pixel != Colors["white"] {
+		t.Errorf("hold frame: seat pixel %s, want white", pixel.Hex())
+	}
+	if pixel := pixelAt(&last, Directions["right"].start); pixel != (Color{}) {
+		t.Errorf("hold frame: other seat pixel %s, want off", pixel.Hex())
+	}
+	// the result is applied only once, however the effect ends
+	finishing := effect.(FinishingEffect)
+	finishing.Finish()
+	finishing.Finish()
+	if finished != 1 {
+		t.Errorf("finish callback called %d times, want once", finished)
+	}
+}
@@ -0,0 +1,110 @@
+//go:build linux
+
+package table
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// linux/gpio.h character device ABI (v1)
+const gpioGetLineEventIoctl = 0xc030b404
+const gpioHandleRequestInput = 1 << 0
+const gpioHandleRequestBiasPullUp = 1 << 5
+const gpioEventRequestFallingEdge = 1 << 1
+const gpioEventEventFallingEdge = 0x02
+
+type gpioEventRequest struct {
+	lineOffset    uint32
+	handleFlags   uint32
+	eventFlags    uint32
+	consumerLabel [32]byte
+	fd            int32
+}
+
+// GpioInput reads button presses from GPIO lines using the Linux GPIO
+// character device, like /dev/gpiochip0.
+type GpioInput struct {
+	presses chan InputPress
+	lines   []*os.File
+	once    sync.Once
+}
+
+// NewGpioInput requests falling edge events for the given line offsets.
+// With pullUp, the internal pull-up resistors are enabled (Linux 5.5+).
+func NewGpioInput(chip string, lines []int, pullUp bool) (*GpioInput, error) {
+	if chip == "" {
+		chip = "/dev/gpiochip0"
+	}
+	device, err := os.Open(chip)
+	if err != nil {
+		return nil, err
+	}
+	defer device.Close()
+	input := new(GpioInput)
+	input.presses = make(chan InputPress, 16)
+	for _, line := range lines {
+		request := gpioEventRequest{}
+		request.lineOffset = uint32(line)
+		request.handleFlags = gpioHandleRequestInput
+		if pullUp {
+			request.handleFlags |= gpioHandleRequestBiasPullUp
+		}
+		request.eventFlags = gpioEventRequestFallingEdge
+		copy(request.consumerLabel[:], "boardgametable")
+		_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, device.Fd(), gpioGetLineEventIoctl, uintptr(unsafe.Pointer(&request)))
+		if errno != 0 {
+			input.Close()
+			return nil, fmt.Errorf("error requesting gpio line %d: %v", line, errno)
+		}
+		file := os.NewFile(uintptr(request.fd), fmt.Sprintf("%s line %d", chip, line))
+		input.lines = append(input.lines, file)
+		go input.read(line, file)
+	}
+	return input, nil
+}
+
+func (gi *GpioInput) read(line int, file *os.File) {
+	name := fmt.Sprintf("gpio:%d", line)
+	// struct gpioevent_data, 64 bit timestamp and 32 bit id padded to 16 bytes
+	data := make([]byte, 16)
+	for {
+		n, err := file.Read(data)
+		if err != nil {
+			return
+		}
+		if n < 12 {
+			continue
+		}
+		id := uint32(data[8]) | uint32(data[9])<<8 | uint32(data[10])<<16 | uint32(data[11])<<24
+		if id != gpioEventEventFallingEdge {
+			continue
+		}
+		// depending on the kernel the timestamp is monotonic or wall clock
+		// time, debouncing only needs the distance between presses
+		timestamp := uint64(0)
+		for i := 7; i >= 0; i-- {
+			timestamp = timestamp<<8 | uint64(data[i])
+		}
+		gi.presses <- InputPress{name, time.Unix(0, int64(timestamp))}
+	}
+}
+
+// Presses returns the channel of presses.
+func (gi *GpioInput) Presses() <-chan InputPress {
+	return gi.presses
+}
+
+// Close releases the GPIO lines.
+func (gi *GpioInput) Close() error {
+	gi.once.Do(func() {
+		for _, file := range gi.lines {
+			file.Close()
+		}
+	})
+	return nil
+}
@@ -0,0 +1,27 @@
+//go:build !linux
+
+package table
+
+import (
+	"errors"
+)
+
+// GpioInput is only supported on Linux.
+type GpioInput struct {
+	presses chan InputPress
+}
+
+// NewGpioInput fails, GPIO input needs the Linux GPIO character device.
+func NewGpioInput(chip string, lines []int, pullUp bool) (*GpioInput, error) {
+	return nil, errors.New("gpio input is only supported on linux")
+}
+
+// Presses returns the channel of presses.
+func (gi *GpioInput) Presses() <-chan InputPress {
+	return gi.presses
+}
+
+// Close does nothing.
+func (gi *GpioInput) Close() error {
+	return nil
+}
@@ -0,0 +1,129 @@
+package table
+
+import (
+	"fmt"
+	"math"
+)
+
+// HighlightStyle selects how the active seat is highlighted.
+type HighlightStyle string
+
+// Available highlight styles.
+const (
+	HighlightPulse     HighlightStyle = "pulse"
+	HighlightChase     HighlightStyle = "chase"
+	HighlightStrobe    HighlightStyle = "strobe"
+	HighlightDimOthers HighlightStyle = "dimothers"
+	HighlightRainbow   HighlightStyle = "rainbow"
+	HighlightBorder    HighlightStyle = "border"
+)
+
+// HighlightStyles lists all available highlight styles.
+var HighlightStyles = []HighlightStyle{HighlightPulse, HighlightChase, HighlightStrobe, HighlightDimOthers, HighlightRainbow, HighlightBorder}
+
+const chaseWidthFraction = 0.2
+const strobePeriodSteps = 20
+const rainbowDegreesPerStep = 2
+const borderWidth = 3
+
+// Highlight describes the highlight of the active seat. Speed scales how fast
+// the effect runs with 1 being the default, intensity ranges from 0 (no
+// visible effect) to 1 (full effect).
+type Highlight struct {
+	Style     HighlightStyle
+	Speed     float64
+	Intensity float64
+}
+
+// DefaultHighlight is the classic pulsing highlight.
+var DefaultHighlight = Highlight{HighlightPulse, 1, 1}
+
+// NewHighlight creates a validated highlight.
+func NewHighlight(style HighlightStyle, speed float64, intensity float64) (Highlight, error) {
+	known := false
+	for _, s := range HighlightStyles {
+		if s == style {
+			known = true
+		}
+	}
+	if !known {
+		return Highlight{}, fmt.Errorf("unknown highlight style %q", style)
+	}
+	if speed <= 0 || speed > 10 {
+		return Highlight{}, fmt.Errorf("highlight speed %g out of range", speed)
+	}
+	if intensity < 0 || intensity > 1 {
+		return Highlight{}, fmt.Errorf("highlight intensity %g out of range", intensity)
+	}
+	return Highlight{style, speed, intensity}, nil
+}
+
+// apply renders the highlight of the active seats into the frame buffer.
+// The phase advances by speed every step and is kept by the caller.
+func (h Highlight) apply(frameBuffer []byte, active []Direction, seats []Direction, phase float64) {
+	if h.Style == HighlightDimOthers {
+		for _, seat := range seats {
+			if !containsDirection(active, seat) {
+				dimRange(frameBuffer, seat, 1-h.Intensity)
+			}
+		}
+		return
+	}
+	for _, direction := range active {
+		h.applyToSeat(frameBuffer, direction, phase)
+	}
+}
+
+func (h Highlight) applyToSeat(frameBuffer []byte, active Direction, phase float64) {
+	length := active.end - active.start
+	switch h.Style {
+	case HighlightPulse:
+		degrees := startFadeDegrees + math.Mod(phase*incrementFadeDegrees, maxFadeDegrees-startFadeDegrees)
+		fade := math.Sin(degrees * math.Pi / 180)
+		dimRange(frameBuffer, active, 1-h.Intensity*(1-fade))
+	case HighlightChase:
+		width := int(math.Max(1, float64(length)*chaseWidthFraction))
+		head := int(phase) % length
+		for i := 0; i < length; i++ {
+			distance := (head - i + length) % length
+			if distance >= width {
+				dimRange(frameBuffer, Direction{active.start + i, active.start + i + 1}, 1-h.Intensity)
+			}
+		}
+	case HighlightStrobe:
+		if int(phase)%strobePeriodSteps >= strobePeriodSteps/2 {
+			dimRange(frameBuffer, active, 1-h.Intensity)
+		}
+	case HighlightRainbow:
+		for i := 0; i < length; i++ {
+			hue := math.Mod(phase*rainbowDegreesPerStep+float64(i)*360/float64(length), 360)
+			blendPixel(frameBuffer, active.start+i, hsvToColor(hue, 1, 1), h.Intensity)
+		}
+	case HighlightBorder:
+		for i := 0; i < length && i < borderWidth; i++ {
+			blendPixel(frameBuffer, active.start+i, Colors["white"], h.Intensity)
+			blendPixel(frameBuffer, active.end-1-i, Colors["white"], h.Intensity)
+		}
+	}
+}
+
+func containsDirection(directions []Direction, direction Direction) bool {
+	for _, d := range directions {
+		if d == direction {
+			return true
+		}
+	}
+	return false
+}
+
+// blendPixel mixes color into a pixel of the frame buffer.
+func blendPixel(frameBuffer []byte, index int, color Color, t float64) {
+	if index < 0 || index*3+2 >= len(frameBuffer) {
+		return
+	}
+	current := Color{frameBuffer[index*3], frameBuffer[index*3+1], frameBuffer[index*3+2]}
+	mixed := mixColors(current, color, t)
+	frameBuffer[index*3] = mixed.r
+	frameBuffer[index*3+1] = mixed.g
+	frameBuffer[index*3+2] = mixed.b
+}
@@ -0,0 +1,98 @@
+package table
+
+import (
+	"testing"
+)
+
+func TestNewHighlight(t *testing.T) {
+	tests := []struct {
+		style     HighlightStyle
+		speed     float64
+		intensity float64
+		valid     bool
+	}{
+		{HighlightPulse, 1, 1, true},
+		{HighlightChase, 0.1, 0, true},
+		{HighlightBorder, 10, 0.5, true},
+		{"blink", 1, 1, false},
+		{HighlightStrobe, 0, 1, false},
+		{HighlightStrobe, 10.5, 1, false},
+		{HighlightRainbow, 1, 1.5, false},
+		{HighlightRainbow, 1, -0.5, false},
+	}
+	for _, test := range tests {
+		_, err := NewHighlight(test.style, test.speed, test.intensity)
+		if (err == nil) != test.valid {
+			t.Errorf("%s speed %g intensity %g: error %v, want valid %v", test.style, test.speed, test.intensity, err, test.valid)
+		}
+	}
+}
+
+func TestHighlightApply(t *testing.T) {
+	active := Direction{0, 10}
+	other := Direction{10, 20}
+	white := Color{255, 255, 255}
+	half := Color{127, 127, 127}
+	tests := []struct {
+		name      string
+		highlight Highlight
+		phase     float64
+		pixels    map[int]Color
+	}{
+		{"dim others", Highlight{HighlightDimOthers, 1, 0.5}, 0, map[int]Color{0: white, 9: white, 10: half, 19: half}},
+		{"dim others off", Highlight{HighlightDimOthers, 1, 1}, 0, map[int]Color{0: white, 10: {}}},
+		{"strobe on", Highlight{HighlightStrobe, 1, 1}, 0, map[int]Color{0: white, 10: white}},
+		{"strobe off", Highlight{HighlightStrobe, 1, 1}, strobePeriodSteps / 2, map[int]Color{0: {}, 9: {}, 10: white}},
+		{"chase head", Highlight{HighlightChase, 1, 1}, 5, map[int]Color{5: white, 4: white, 3: {}, 6: {}, 10: white}},
+		{"border", Highlight{HighlightBorder, 1, 1}, 0, map[int]Color{0: white, 2: white, 3: Color{255, 0, 0}, 7: white, 9: white}},
+		{"pulse without intensity", Highlight{HighlightPulse, 1, 0}, 7, map[int]Color{0: white, 10: white}},
+		{"rainbow without intensity", Highlight{HighlightRainbow, 1, 0}, 7, map[int]Color{0: white, 10: white}},
+		{"rainbow", Highlight{HighlightRainbow, 1, 1}, 0, map[int]Color{0: {255, 0, 0}, 10: white}},
+	}
+	for _, test := range tests {
+		frameBuffer := make([]byte, 20*3)
+		for i := range frameBuffer {
+			frameBuffer[i] = 255
+		}
+		if test.highlight.Style == HighlightBorder {
+			SolidFill(Color{255, 0, 0}).paint(frameBuffer, active)
+		}
+		test.highlight.apply(frameBuffer, []Direction{active}, []Direction{active, other}, test.phase)
+		for index, want := range test.pixels {
+			if pixel := pixelAt(&frameBuffer, index); pixel != want {
+				t.Errorf("%s: pixel %d is %s, want %s", test.name, index, pixel.Hex(), want.Hex())
+			}
+		}
+	}
+}
+
+func TestPlayTableHighlight(t *testing.T) {
+	leds := NewLeds(NewFakeOutput(), DefaultLedCount)
+	playTable := NewAnimationPlayTable(leds.GetFrameBuffer(), DefaultLayout)
+	if playTable.GetHighlight() != DefaultHighlight {
+		t.Errorf("highlight %+v, want the default", playTable.GetHighlight())
+	}
+	for _, name := range SeatNames {
+		err := playTable.SetPlayerColor(Directions[name], Colors["white"])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	highlight, err := NewHighlight(HighlightDimOthers, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	playTable.SetHighlight(highlight)
+	err = playTable.SetActiveDirection(Directions["left"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	playTable.Step()
+	frameBuffer := leds.GetFrameBuffer()
+	if pixel := pixelAt(frameBuffer, Directions["left"].start); pixel != Colors["white"] {
+		t.Errorf("active seat pixel %s, want white", pixel.Hex())
+	}
+	if pixel := pixelAt(frameBuffer, Directions["right"].start); pixel != (Color{}) {
+		t.Errorf("inactive seat pixel %s, want off", pixel.Hex())
+	}
+}
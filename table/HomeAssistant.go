@@ -0,0 +1,321 @@
+package table
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"boardgametable/mqtt"
+)
+
+const mqttStateInterval = 2 * time.Second
+
+// effects offered to Home Assistant, ambient modes and one-shot effects
+var mqttEffects = []string{"playtable", "breathing", "warmwhite", "colorcycle", "dice", "critical", "victory"}
+
+// MqttBridge exposes the table as a Home Assistant light over MQTT. It
+// publishes the state and discovery payloads and executes commands.
+type MqttBridge struct {
+	client          *mqtt.Client
+	leds            *Leds
+	idleMonitor     *IdleMonitor
+	name            string
+	baseTopic       string
+	discoveryPrefix string
+	nodeID          string
+	lastState       string
+	lastActive      string
+	offPrevious     Animation
+	playTable       *AnimationPlayTable
+	// guards the fields above, used by the state ticker and the command handlers
+	lock sync.Mutex
+}
+
+type mqttLightState struct {
+	State      string `json:"state"`
+	Brightness int    `json:"brightness"`
+	Effect     string `json:"effect,omitempty"`
+}
+
+// NewMqttBridge connects to the broker and starts publishing. name is the
+// device name shown in Home Assistant, baseTopic the prefix of the state
+// and command topics, discoveryPrefix the Home Assistant discovery prefix,
+// usually "homeassistant". Commands count as activity for the idle monitor,
+// which may be nil.
+func NewMqttBridge(leds *Leds, idleMonitor *IdleMonitor, name string, brokerURL string, baseTopic string, discoveryPrefix string) (*MqttBridge, error) {
+	bridge := new(MqttBridge)
+	bridge.leds = leds
+	bridge.idleMonitor = idleMonitor
+	bridge.name = name
+	bridge.baseTopic = strings.TrimSuffix(baseTopic, "/")
+	bridge.discoveryPrefix = strings.TrimSuffix(discoveryPrefix, "/")
+	bridge.nodeID = strings.Replace(bridge.baseTopic, "/", "_", -1)
+	client, err := mqtt.NewClient(brokerURL, bridge.nodeID, &mqtt.Will{
+		Topic:   bridge.baseTopic + "/availability",
+		Payload: []byte("offline"),
+		Retain:  true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	bridge.client = client
+	client.Subscribe(bridge.baseTopic+"/set", bridge.handleLightCommand)
+	client.Subscribe(bridge.baseTopic+"/active/set", bridge.handleActiveCommand)
+	client.Subscribe(bridge.baseTopic+"/next/set", bridge.handleNextCommand)
+	client.OnConnect(bridge.announce)
+	err = client.Start()
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		for {
+			time.Sleep(mqttStateInterval)
+			bridge.PublishState(false)
+		}
+	}()
+	return bridge, nil
+}
+
+// announce publishes the availability and the discovery payloads.
+func (mb *MqttBridge) announce() {
+	device := map[string]interface{}{
+		"identifiers":  []string{mb.nodeID},
+		"name":         mb.name,
+		"model":        outputModel(mb.leds.GetOutput()),
+		"manufacturer": "boardgametable",
+	}
+	availability := mb.baseTopic + "/availability"
+	configs := map[string]map[string]interface{}{
+		"light": {
+			"name":               mb.name,
+			"unique_id":          mb.nodeID + "_light",
+			"schema":             "json",
+			"command_topic":      mb.baseTopic + "/set",
+			"state_topic":        mb.baseTopic + "/state",
+			"availability_topic": availability,
+			"brightness":         true,
+			"effect":             true,
+			"effect_list":        mqttEffects,
+			"device":             device,
+		},
+		"select": {
+			"name":               mb.name + " Active Seat",
+			"unique_id":          mb.nodeID + "_active_seat",
+			"command_topic":      mb.baseTopic + "/active/set",
+			"state_topic":        mb.baseTopic + "/active",
+			"availability_topic": availability,
+			"options":            append([]string{"none"}, SeatNames...),
+			"device":             device,
+		},
+		"button": {
+			"name":               mb.name + " Next Turn",
+			"unique_id":          mb.nodeID + "_next_turn",
+			"command_topic":      mb.baseTopic + "/next/set",
+			"availability_topic": availability,
+			"device":             device,
+		},
+	}
+	for component, config := range configs {
+		payload, _ := json.Marshal(config)
+		topic := fmt.Sprintf("%s/%s/%s/%s/config", mb.discoveryPrefix, component, mb.nodeID, component)
+		err := mb.client.Publish(topic, payload, true)
+		if err != nil {
+			fmt.Println("error publishing mqtt discovery:", err)
+		}
+	}
+	mb.client.Publish(availability, []byte("online"), true)
+	mb.PublishState(true)
+}
+
+// outputModel returns the controller type shown as device model.
+func outputModel(output Output) string {
+	switch output.(type) {
+	case *Sp108e:
+		return "SP108E"
+	case *Wled:
+		return "WLED"
+	case *Ddp:
+		return "DDP"
+	case *E131:
+		return "E1.31"
+	case *ArtNet:
+		return "Art-Net"
+	case *VirtualStrip:
+		return "Virtual strip"
+	}
+	return "LED strip"
+}
+
+// touch records the command as activity, an ambient animation started by
+// the idle monitor or the schedule ends before the command is executed.
+func (mb *MqttBridge) touch() {
+	if mb.idleMonitor == nil {
+		return
+	}
+	err := mb.idleMonitor.Touch()
+	if err != nil {
+		fmt.Println("error restoring animation:", err)
+	}
+}
+
+// state returns the current light state and the active seat. The lock has
+// to be held.
+func (mb *MqttBridge) state() (mqttLightState, string) {
+	state := mqttLightState{"OFF", int(mb.leds.GetBrightness()), ""}
+	active := "none"
+	if mb.leds.IsAnimationRunning() {
+		state.State = "ON"
+		switch animation := mb.leds.GetCurrentAnimation().(type) {
+		case *AnimationPlayTable:
+			state.Effect = "playtable"
+			if direction, ok := animation.GetActiveDirection(); ok {
+				active = animation.GetLayout().SeatName(direction)
+			}
+		case *AnimationAmbient:
+			state.Effect = string(animation.GetMode())
+			if animation.GetMode() == AmbientOff {
+				state.State = "OFF"
+				state.Effect = ""
+			}
+		}
+	}
+	return state, active
+}
+
+// PublishState publishes the state if it has changed or force is set.
+func (mb *MqttBridge) PublishState(force bool) {
+	mb.lock.Lock()
+	defer mb.lock.Unlock()
+	state, active := mb.state()
+	payload, _ := json.Marshal(state)
+	if force || string(payload) != mb.lastState {
+		if mb.client.Publish(mb.baseTopic+"/state", payload, true) == nil {
+			mb.lastState = string(payload)
+		}
+	}
+	if force || active != mb.lastActive {
+		if mb.client.Publish(mb.baseTopic+"/active", []byte(active), true) == nil {
+			mb.lastActive = active
+		}
+	}
+}
+
+func (mb *MqttBridge) handleLightCommand(topic string, payload []byte) {
+	var command struct {
+		State      string `json:"state"`
+		Brightness *int   `json:"brightness"`
+		Effect     string `json:"effect"`
+	}
+	err := json.Unmarshal(payload, &command)
+	if err != nil {
+		fmt.Println("invalid mqtt light command:", err)
+		return
+	}
+	mb.touch()
+	err = mb.executeLightCommand(command.State, command.Brightness, command.Effect)
+	if err != nil {
+		fmt.Println("error executing mqtt light command:", err)
+	}
+	mb.PublishState(false)
+}
+
+func (mb *MqttBridge) executeLightCommand(state string, brightness *int, effect string) error {
+	mb.lock.Lock()
+	defer mb.lock.Unlock()
+	if state == "OFF" {
+		if mb.leds.IsAnimationRunning() {
+			mb.offPrevious = mb.leds.GetCurrentAnimation()
+		}
+		return mb.leds.StartAnimation(NewAnimationAmbient(mb.leds.GetFrameBuffer(), AmbientOff))
+	}
+	if brightness != nil {
+		if *brightness < 0 || *brightness > 255 {
+			return fmt.Errorf("brightness %d out of range", *brightness)
+		}
+		err := mb.leds.SetBrightness(byte(*brightness))
+		if err != nil {
+			return err
+		}
+	}
+	switch effect {
+	case "":
+		if state == "ON" {
+			if ambient, ok := mb.leds.GetCurrentAnimation().(*AnimationAmbient); !mb.leds.IsAnimationRunning() || (ok && ambient.GetMode() == AmbientOff) {
+				return mb.restore()
+			}
+		}
+		return nil
+	case "playtable":
+		return mb.restore()
+	case "dice":
+		return mb.leds.PlayEffect(NewShimmerEffect(Colors["white"]))
+	case "critical":
+		return mb.leds.PlayEffect(NewFlashEffect(Colors["red"], 3))
+	case "victory":
+		return mb.leds.PlayEffect(NewSweepEffect())
+	}
+	mode, err := ParseAmbientMode(effect)
+	if err != nil {
+		return err
+	}
+	if playTable, ok := mb.leds.GetCurrentAnimation().(*AnimationPlayTable); ok {
+		mb.playTable = playTable
+	}
+	return mb.leds.StartAnimation(NewAnimationAmbient(mb.leds.GetFrameBuffer(), mode))
+}
+
+// restore brings back the animation that ran before the light was turned
+// off or switched to an ambient effect, falling back to warm white. The
+// lock has to be held.
+func (mb *MqttBridge) restore() error {
+	previous := mb.offPrevious
+	mb.offPrevious = nil
+	if _, ok := previous.(*AnimationPlayTable); !ok && mb.playTable != nil {
+		previous = mb.playTable
+	}
+	if previous == nil {
+		previous = NewAnimationAmbient(mb.leds.GetFrameBuffer(), AmbientWarmWhite)
+	}
+	previous.SetFrameBuffer(mb.leds.GetFrameBuffer())
+	return mb.leds.StartAnimation(previous)
+}
+
+func (mb *MqttBridge) handleActiveCommand(topic string, payload []byte) {
+	mb.touch()
+	playTable, ok := mb.leds.GetCurrentAnimation().(*AnimationPlayTable)
+	if !ok {
+		fmt.Println("mqtt active seat: current animation does not support active direction")
+		return
+	}
+	seat := strings.TrimSpace(string(payload))
+	var err error
+	if seat == "none" {
+		if _, active := playTable.GetActiveDirection(); active {
+			err = playTable.ActiveDirectionOff()
+		}
+	} else if direction, ok := playTable.GetLayout().Seat(seat); ok {
+		err = playTable.SetActiveDirection(direction)
+	} else {
+		err = fmt.Errorf("unknown seat %q", seat)
+	}
+	if err != nil {
+		fmt.Println("error executing mqtt active seat command:", err)
+	}
+	mb.PublishState(false)
+}
+
+func (mb *MqttBridge) handleNextCommand(topic string, payload []byte) {
+	mb.touch()
+	playTable, ok := mb.leds.GetCurrentAnimation().(*AnimationPlayTable)
+	if !ok {
+		fmt.Println("mqtt next turn: current animation does not support active direction")
+		return
+	}
+	err := playTable.ActiveDirectionNext()
+	if err != nil {
+		fmt.Println("error executing mqtt next turn command:", err)
+	}
+	mb.PublishState(false)
+}
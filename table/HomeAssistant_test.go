@@ -0,0 +1,82 @@
+package table
+
+import (
+	"testing"
+)
+
+func TestOutputModel(t *testing.T) {
+	tests := []struct {
+		output Output
+		model  string
+	}{
+		{&Sp108e{}, "SP108E"},
+		{&Wled{}, "WLED"},
+		{&Ddp{}, "DDP"},
+		{&E131{}, "E1.31"},
+		{&ArtNet{}, "Art-Net"},
+		{&VirtualStrip{}, "Virtual strip"},
+		{NewFakeOutput(), "LED strip"},
+	}
+	for _, test := range tests {
+		if model := outputModel(test.output); model != test.model {
+			t.Errorf("%T: model %q, want %q", test.output, model, test.model)
+		}
+	}
+}
+
+func TestMqttLightCommands(t *testing.T) {
+	leds, _, playTable := newTestTable(t)
+	defer leds.StopAnimation()
+	idleMonitor := NewIdleMonitor(leds, 0, AmbientBreathing)
+	bridge := &MqttBridge{leds: leds, idleMonitor: idleMonitor}
+	err := playTable.SetActiveDirection(Directions["left"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		state  string
+		effect string
+		want   mqttLightState
+		active string
+	}{
+		{"play table", "ON", "", mqttLightState{"ON", 255, "playtable"}, "left"},
+		{"ambient", "ON", "warmwhite", mqttLightState{"ON", 255, "warmwhite"}, "none"},
+		{"back to the play table", "ON", "playtable", mqttLightState{"ON", 255, "playtable"}, "left"},
+		{"off", "OFF", "", mqttLightState{"OFF", 255, ""}, "none"},
+		{"on again", "ON", "", mqttLightState{"ON", 255, "playtable"}, "left"},
+	}
+	for _, test := range tests {
+		err = bridge.executeLightCommand(test.state, nil, test.effect)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		bridge.lock.Lock()
+		state, active := bridge.state()
+		bridge.lock.Unlock()
+		if state != test.want || active != test.active {
+			t.Errorf("%s: state %+v and seat %s, want %+v and %s", test.name, state, active, test.want, test.active)
+		}
+	}
+	if err = bridge.executeLightCommand("ON", nil, "fireworks"); err == nil {
+		t.Error("expected an error for an unknown effect")
+	}
+}
+
+func TestMqttCommandTouchesIdleMonitor(t *testing.T) {
+	leds, _, playTable := newTestTable(t)
+	defer leds.StopAnimation()
+	idleMonitor := NewIdleMonitor(leds, 0, AmbientBreathing)
+	bridge := &MqttBridge{leds: leds, idleMonitor: idleMonitor}
+	err := idleMonitor.StartAmbient(AmbientColorCycle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bridge.touch()
+	if idleMonitor.IsIdle() {
+		t.Error("still idle after an mqtt command")
+	}
+	if leds.GetCurrentAnimation() != Animation(playTable) {
+		t.Errorf("animation %T after an mqtt command, want the play table", leds.GetCurrentAnimation())
+	}
+}
@@ -0,0 +1,113 @@
+package table
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const idleCheckInterval = 5 * time.Second
+
+// IdleMonitor switches to an ambient animation when the table has not been
+// used for a while and restores the previous animation on the next use.
+type IdleMonitor struct {
+	leds         *Leds
+	timeout      time.Duration
+	mode         AmbientMode
+	lastActivity time.Time
+	idle         bool
+	previous     Animation
+	lock         sync.Mutex
+}
+
+// NewIdleMonitor creates and starts an idle monitor. A timeout of 0 disables it.
+func NewIdleMonitor(leds *Leds, timeout time.Duration, mode AmbientMode) *IdleMonitor {
+	monitor := new(IdleMonitor)
+	monitor.leds = leds
+	monitor.timeout = timeout
+	monitor.mode = mode
+	monitor.lastActivity = time.Now()
+	go func() {
+		for {
+			time.Sleep(idleCheckInterval)
+			monitor.check()
+		}
+	}()
+	return monitor
+}
+
+// Configure changes the timeout and the ambient mode.
+func (im *IdleMonitor) Configure(timeout time.Duration, mode AmbientMode) {
+	im.lock.Lock()
+	defer im.lock.Unlock()
+	im.timeout = timeout
+	im.mode = mode
+	im.lastActivity = time.Now()
+}
+
+// IsIdle returns true while the ambient animation is shown.
+func (im *IdleMonitor) IsIdle() bool {
+	im.lock.Lock()
+	defer im.lock.Unlock()
+	return im.idle
+}
+
+// Touch records activity and restores the previous animation if idle.
+func (im *IdleMonitor) Touch() error {
+	im.lock.Lock()
+	defer im.lock.Unlock()
+	im.lastActivity = time.Now()
+	if !im.idle {
+		return nil
+	}
+	im.idle = false
+	previous := im.previous
+	im.previous = nil
+	if previous == nil {
+		// nothing was shown before, turn the ambient light off
+		fmt.Println("activity detected, ending ambient animation")
+		return im.leds.Blank()
+	}
+	fmt.Println("activity detected, restoring previous animation")
+	// the ambient animation has painted over the shared frame buffer
+	previous.SetFrameBuffer(im.leds.GetFrameBuffer())
+	return im.leds.StartAnimation(previous)
+}
+
+func (im *IdleMonitor) check() {
+	im.lock.Lock()
+	defer im.lock.Unlock()
+	if im.idle || im.timeout <= 0 || time.Since(im.lastActivity) < im.timeout {
+		return
+	}
+	fmt.Println("table idle, starting ambient animation:", im.mode)
+	err := im.startAmbient(im.mode)
+	if err != nil {
+		fmt.Println("error starting ambient animation:", err)
+	}
+}
+
+// StartAmbient shows the ambient animation right away as if the table was
+// idle, the previous animation is restored on the next activity.
+func (im *IdleMonitor) StartAmbient(mode AmbientMode) error {
+	im.lock.Lock()
+	defer im.lock.Unlock()
+	return im.startAmbient(mode)
+}
+
+// startAmbient keeps the animation shown before the table went idle and
+// starts the ambient animation. The lock has to be held.
+func (im *IdleMonitor) startAmbient(mode AmbientMode) error {
+	if !im.idle {
+		im.previous = nil
+		if im.leds.IsAnimationRunning() {
+			im.previous = im.leds.GetCurrentAnimation()
+		}
+	}
+	err := im.leds.StartAnimation(NewAnimationAmbient(im.leds.GetFrameBuffer(), mode))
+	if err != nil {
+		return err
+	}
+	im.idle = true
+	return nil
+}
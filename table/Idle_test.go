@@ -0,0 +1,73 @@
+package table
+
+import (
+	"testing"
+	"time"
+)
+
+// makeIdle pretends the last activity was long ago and runs the idle check.
+func makeIdle(monitor *IdleMonitor) {
+	monitor.lock.Lock()
+	monitor.lastActivity = time.Now().Add(-time.Hour)
+	monitor.lock.Unlock()
+	monitor.check()
+}
+
+func TestIdleMonitor(t *testing.T) {
+	leds, _, playTable := newTestTable(t)
+	defer leds.StopAnimation()
+	monitor := NewIdleMonitor(leds, 0, AmbientWarmWhite)
+	// a timeout of 0 disables the monitor
+	makeIdle(monitor)
+	if monitor.IsIdle() {
+		t.Fatal("disabled monitor went idle")
+	}
+	monitor.Configure(time.Minute, AmbientColorCycle)
+	monitor.check()
+	if monitor.IsIdle() {
+		t.Fatal("monitor went idle before the timeout")
+	}
+	makeIdle(monitor)
+	if !monitor.IsIdle() {
+		t.Fatal("monitor not idle after the timeout")
+	}
+	ambient, ok := leds.GetCurrentAnimation().(*AnimationAmbient)
+	if !ok || ambient.GetMode() != AmbientColorCycle {
+		t.Fatalf("animation %T, want the color cycle", leds.GetCurrentAnimation())
+	}
+	// switching the ambient mode keeps the animation to restore
+	err := monitor.StartAmbient(AmbientBreathing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = monitor.Touch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if monitor.IsIdle() {
+		t.Error("monitor still idle after activity")
+	}
+	if leds.GetCurrentAnimation() != Animation(playTable) {
+		t.Errorf("animation %T after activity, want the play table", leds.GetCurrentAnimation())
+	}
+	if playTable.GetFrameBuffer() != leds.GetFrameBuffer() {
+		t.Error("restored animation does not paint the shared frame buffer")
+	}
+}
+
+func TestIdleMonitorWithoutAnimation(t *testing.T) {
+	leds := NewLeds(NewFakeOutput(), DefaultLedCount)
+	defer leds.StopAnimation()
+	monitor := NewIdleMonitor(leds, time.Minute, AmbientWarmWhite)
+	makeIdle(monitor)
+	if !monitor.IsIdle() {
+		t.Fatal("monitor not idle after the timeout")
+	}
+	err := monitor.Touch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leds.IsAnimationRunning() {
+		t.Errorf("animation %T running after activity, want the lights off", leds.GetCurrentAnimation())
+	}
+}
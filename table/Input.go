@@ -0,0 +1,295 @@
+package table
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultDebounce = 50 * time.Millisecond
+
+// InputPress is a button or key press reported by an input source. Name
+// identifies the input, like "gpio:17".
+type InputPress struct {
+	Name string
+	Time time.Time
+}
+
+// InputSource delivers presses from an input device.
+type InputSource interface {
+	Presses() <-chan InputPress
+	Close() error
+}
+
+// InputConfig is the input configuration file. Bindings map input lines
+// or keys to actions, see ParseInputAction.
+type InputConfig struct {
+	Debounce  int              `json:"debounce"`
+	Gpio      *GpioConfig      `json:"gpio,omitempty"`
+	Keyboards []KeyboardConfig `json:"keyboards,omitempty"`
+}
+
+// GpioConfig configures buttons on a GPIO chip, mapping line offsets to
+// actions. Buttons connect the line to ground, so a press is a falling edge.
+// Table is the id of the table the buttons control, empty for the first.
+type GpioConfig struct {
+	Chip    string            `json:"chip"`
+	PullUp  bool              `json:"pullup"`
+	Table   string            `json:"table,omitempty"`
+	Buttons map[string]string `json:"buttons"`
+}
+
+// KeyboardConfig configures an evdev device like a keyboard, a macro
+// keypad or a foot pedal, mapping key names to actions. Table is the id of
+// the table the keys control, empty for the first.
+type KeyboardConfig struct {
+	Device string            `json:"device"`
+	Grab   bool              `json:"grab"`
+	Table  string            `json:"table,omitempty"`
+	Keys   map[string]string `json:"keys"`
+}
+
+// InputAction is something a button press does on a table, Table is empty
+// for the default table of the controller.
+type InputAction struct {
+	Name  string
+	Seat  string
+	Table string
+}
+
+// ParseInputAction parses an action. Actions are next, previous, skipnext,
+// reverse, off, nextphase, pause (freeze or resume the lights, there is no turn timer),
+// seat:<seat> (activate the seat or mark it done
+// in a simultaneous phase) and effect:<dice|critical|victory>.
+func ParseInputAction(encoded string) (InputAction, error) {
+	parts := strings.SplitN(strings.TrimSpace(encoded), ":", 2)
+	action := InputAction{Name: parts[0]}
+	switch action.Name {
+	case "next", "previous", "skipnext", "reverse", "off", "nextphase", "pause":
+		if len(parts) == 1 {
+			return action, nil
+		}
+	case "seat":
+		if len(parts) == 2 && isSeatName(parts[1]) {
+			action.Seat = parts[1]
+			return action, nil
+		}
+	case "effect":
+		if len(parts) == 2 && (parts[1] == "dice" || parts[1] == "critical" || parts[1] == "victory") {
+			action.Name = encoded
+			return action, nil
+		}
+	}
+	return action, fmt.Errorf("invalid input action %q", encoded)
+}
+
+// InputController executes the actions bound to presses of its sources.
+// Presses of the same input closer together than the debounce time are
+// ignored.
+type InputController struct {
+	leds      *Leds
+	tables    map[string]*Leds
+	debounce  time.Duration
+	bindings  map[string]InputAction
+	lastPress map[string]time.Time
+	onPress   func(table string)
+	lock      sync.Mutex
+}
+
+// NewInputController creates an input controller without bindings. leds is
+// the default table, further tables are added with AddTable.
+func NewInputController(leds *Leds, debounce time.Duration) *InputController {
+	controller := new(InputController)
+	controller.leds = leds
+	controller.tables = map[string]*Leds{}
+	controller.debounce = debounce
+	controller.bindings = map[string]InputAction{}
+	controller.lastPress = map[string]time.Time{}
+	return controller
+}
+
+// LoadInputs reads the input configuration from path, opens the configured
+// devices and starts executing bound actions. Devices without a table
+// control leds, the others the table of tables with their id.
+func LoadInputs(leds *Leds, tables map[string]*Leds, path string) (*InputController, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := InputConfig{}
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid input file %s: %v", path, err)
+	}
+	debounce := defaultDebounce
+	if config.Debounce > 0 {
+		debounce = time.Duration(config.Debounce) * time.Millisecond
+	}
+	controller := NewInputController(leds, debounce)
+	for id, table := range tables {
+		controller.AddTable(id, table)
+	}
+	if config.Gpio != nil {
+		lines := []int{}
+		for line, action := range config.Gpio.Buttons {
+			var offset int
+			_, err := fmt.Sscanf(line, "%d", &offset)
+			if err != nil {
+				return nil, fmt.Errorf("invalid gpio line %q", line)
+			}
+			err = controller.BindTable(fmt.Sprintf("gpio:%d", offset), config.Gpio.Table, action)
+			if err != nil {
+				return nil, err
+			}
+			lines = append(lines, offset)
+		}
+		source, err := NewGpioInput(config.Gpio.Chip, lines, config.Gpio.PullUp)
+		if err != nil {
+			return nil, err
+		}
+		controller.Attach(source)
+	}
+	for _, keyboard := range config.Keyboards {
+		for key, action := range keyboard.Keys {
+			code, err := ParseKeyCode(key)
+			if err != nil {
+				return nil, err
+			}
+			err = controller.BindTable(KeyInputName(keyboard.Device, code), keyboard.Table, action)
+			if err != nil {
+				return nil, err
+			}
+		}
+		source, err := NewKeyboardInput(keyboard.Device, keyboard.Grab)
+		if err != nil {
+			return nil, err
+		}
+		controller.Attach(source)
+	}
+	return controller, nil
+}
+
+// AddTable makes a table available to bindings under its id.
+func (ic *InputController) AddTable(id string, leds *Leds) {
+	ic.lock.Lock()
+	defer ic.lock.Unlock()
+	ic.tables[id] = leds
+}
+
+// Bind binds an action on the default table to an input.
+func (ic *InputController) Bind(input string, action string) error {
+	return ic.BindTable(input, "", action)
+}
+
+// BindTable binds an action on the table with the given id to an input, an
+// empty id is the default table.
+func (ic *InputController) BindTable(input string, table string, action string) error {
+	parsed, err := ParseInputAction(action)
+	if err != nil {
+		return err
+	}
+	ic.lock.Lock()
+	defer ic.lock.Unlock()
+	if _, ok := ic.tables[table]; table != "" && !ok {
+		return fmt.Errorf("input %s: unknown table %q", input, table)
+	}
+	parsed.Table = table
+	ic.bindings[input] = parsed
+	return nil
+}
+
+// OnPress sets a function called with the table id for every accepted
+// press, before the action is executed.
+func (ic *InputController) OnPress(onPress func(table string)) {
+	ic.lock.Lock()
+	defer ic.lock.Unlock()
+	ic.onPress = onPress
+}
+
+// Attach starts executing the presses of source.
+func (ic *InputController) Attach(source InputSource) {
+	go func() {
+		for press := range source.Presses() {
+			err := ic.Handle(press)
+			if err != nil {
+				fmt.Println("error executing input action:", err)
+			}
+		}
+	}()
+}
+
+// Handle debounces a press and executes the bound action.
+func (ic *InputController) Handle(press InputPress) error {
+	ic.lock.Lock()
+	action, bound := ic.bindings[press.Name]
+	last, pressed := ic.lastPress[press.Name]
+	if pressed && press.Time.Sub(last) < ic.debounce {
+		ic.lock.Unlock()
+		return nil
+	}
+	ic.lastPress[press.Name] = press.Time
+	onPress := ic.onPress
+	leds := ic.leds
+	if action.Table != "" {
+		leds = ic.tables[action.Table]
+	}
+	ic.lock.Unlock()
+	if !bound {
+		return nil
+	}
+	if onPress != nil {
+		onPress(action.Table)
+	}
+	return execute(leds, action)
+}
+
+func execute(leds *Leds, action InputAction) error {
+	switch action.Name {
+	case "effect:dice":
+		return leds.PlayEffect(NewShimmerEffect(Colors["white"]))
+	case "effect:critical":
+		return leds.PlayEffect(NewFlashEffect(Colors["red"], 3))
+	case "effect:victory":
+		return leds.PlayEffect(NewSweepEffect())
+	case "pause":
+		if !leds.IsAnimationRunning() {
+			return fmt.Errorf("no animation to pause")
+		}
+		leds.SetPaused(!leds.IsPaused())
+		return nil
+	}
+	playTable, ok := leds.GetCurrentAnimation().(*AnimationPlayTable)
+	if !ok {
+		return fmt.Errorf("current animation does not support active direction")
+	}
+	switch action.Name {
+	case "next":
+		return playTable.ActiveDirectionNext()
+	case "previous":
+		return playTable.ActiveDirectionPrevious()
+	case "skipnext":
+		return playTable.ActiveDirectionSkipNext()
+	case "reverse":
+		playTable.Reverse()
+		return nil
+	case "off":
+		return playTable.ActiveDirectionOff()
+	case "nextphase":
+		_, err := playTable.PhaseNext()
+		return err
+	case "seat":
+		direction, ok := playTable.GetLayout().Seat(action.Seat)
+		if !ok {
+			return fmt.Errorf("no seat %s at the table", action.Seat)
+		}
+		if playTable.IsSimultaneousPhase() {
+			_, err := playTable.MarkDone(direction)
+			return err
+		}
+		return playTable.SetActiveDirection(direction)
+	}
+	return fmt.Errorf("unknown input action %q", action.Name)
+}
@@ -0,0 +1,239 @@
+package table
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestTable starts a play table with colored seats on a fake output.
+func newTestTable(t *testing.T) (*Leds, *FakeOutput, *AnimationPlayTable) {
+	output := NewFakeOutput()
+	leds := NewLeds(output, DefaultLedCount)
+	playTable := NewAnimationPlayTable(leds.GetFrameBuffer(), DefaultLayout)
+	for _, name := range SeatNames {
+		err := playTable.SetPlayerColor(Directions[name], Colors["blue"])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := leds.StartAnimation(playTable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leds, output, playTable
+}
+
+// activeSeat returns the name of the active seat.
+func activeSeat(playTable *AnimationPlayTable) string {
+	direction, ok := playTable.GetActiveDirection()
+	if !ok {
+		return ""
+	}
+	return playTable.GetLayout().SeatName(direction)
+}
+
+// waitFor polls condition until it holds or a second has passed.
+func waitFor(t *testing.T, what string, condition func() bool) {
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestParseInputAction(t *testing.T) {
+	tests := []struct {
+		encoded string
+		valid   bool
+	}{
+		{"next", true},
+		{"previous", true},
+		{"seat:left", true},
+		{"effect:dice", true},
+		{"pause", true},
+		{"seat:kitchen", false},
+		{"effect:fireworks", false},
+		{"next:left", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		_, err := ParseInputAction(test.encoded)
+		if (err == nil) != test.valid {
+			t.Errorf("ParseInputAction(%q) error %v, want valid %v", test.encoded, err, test.valid)
+		}
+	}
+}
+
+func TestInputBindings(t *testing.T) {
+	leds, _, playTable := newTestTable(t)
+	err := playTable.SetActiveDirection(Directions["right"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	controller := NewInputController(leds, 50*time.Millisecond)
+	for input, action := range map[string]string{"gpio:17": "next", "gpio:22": "previous", "gpio:27": "effect:dice", "gpio:23": "seat:top"} {
+		err := controller.Bind(input, action)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	var presses int32
+	controller.OnPress(func(table string) {
+		atomic.AddInt32(&presses, 1)
+	})
+	input := NewFakeInput()
+	defer input.Close()
+	controller.Attach(input)
+
+	input.Press("gpio:17")
+	waitFor(t, "next seat", func() bool { return activeSeat(playTable) == "bottom" })
+	input.Press("gpio:22")
+	waitFor(t, "previous seat", func() bool { return activeSeat(playTable) == "right" })
+	input.Press("gpio:23")
+	waitFor(t, "top seat", func() bool { return activeSeat(playTable) == "top" })
+	input.Press("gpio:27")
+	waitFor(t, "dice effect", func() bool {
+		leds.effectLock.Lock()
+		defer leds.effectLock.Unlock()
+		_, ok := leds.effect.(*shimmerEffect)
+		return ok
+	})
+	// unbound inputs are ignored
+	input.Press("gpio:5")
+	waitFor(t, "bound presses", func() bool { return atomic.LoadInt32(&presses) == 4 })
+}
+
+func TestInputTables(t *testing.T) {
+	leds, _, playTable := newTestTable(t)
+	kitchenLeds, _, kitchenTable := newTestTable(t)
+	for _, table := range []*AnimationPlayTable{playTable, kitchenTable} {
+		err := table.SetActiveDirection(Directions["right"])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	controller := NewInputController(leds, 0)
+	controller.AddTable("kitchen", kitchenLeds)
+	if err := controller.BindTable("gpio:5", "garage", "next"); err == nil {
+		t.Error("expected an error binding an unknown table")
+	}
+	err := controller.Bind("gpio:17", "next")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = controller.BindTable("gpio:22", "kitchen", "seat:top")
+	if err != nil {
+		t.Fatal(err)
+	}
+	touched := []string{}
+	controller.OnPress(func(table string) {
+		touched = append(touched, table)
+	})
+	for _, input := range []string{"gpio:17", "gpio:22"} {
+		err = controller.Handle(InputPress{input, time.Now()})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if seat := activeSeat(playTable); seat != "bottom" {
+		t.Errorf("active seat %q on the default table, want bottom", seat)
+	}
+	if seat := activeSeat(kitchenTable); seat != "top" {
+		t.Errorf("active seat %q on the kitchen table, want top", seat)
+	}
+	if len(touched) != 2 || touched[0] != "" || touched[1] != "kitchen" {
+		t.Errorf("presses reported for tables %q, want the default and kitchen", touched)
+	}
+}
+
+func TestInputDebounce(t *testing.T) {
+	leds, _, playTable := newTestTable(t)
+	err := playTable.SetActiveDirection(Directions["right"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	controller := NewInputController(leds, 50*time.Millisecond)
+	err = controller.Bind("key:pedal:48", "next")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	tests := []struct {
+		offset time.Duration
+		seat   string
+	}{
+		{0, "bottom"},
+		// bounces within the debounce time are ignored
+		{10 * time.Millisecond, "bottom"},
+		{40 * time.Millisecond, "bottom"},
+		{100 * time.Millisecond, "left"},
+		{160 * time.Millisecond, "top"},
+	}
+	for _, test := range tests {
+		err := controller.Handle(InputPress{"key:pedal:48", start.Add(test.offset)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seat := activeSeat(playTable); seat != test.seat {
+			t.Errorf("press at %v: active seat %q, want %q", test.offset, seat, test.seat)
+		}
+	}
+}
+
+func TestFakeInputDebounce(t *testing.T) {
+	leds, _, playTable := newTestTable(t)
+	err := playTable.SetActiveDirection(Directions["right"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	controller := NewInputController(leds, 50*time.Millisecond)
+	controller.Bind("gpio:17", "next")
+	input := NewFakeInput()
+	controller.Attach(input)
+	start := time.Now()
+	input.PressAt("gpio:17", start)
+	input.PressAt("gpio:17", start.Add(5*time.Millisecond))
+	input.PressAt("gpio:17", start.Add(20*time.Millisecond))
+	input.Close()
+	waitFor(t, "first press", func() bool { return activeSeat(playTable) == "bottom" })
+	time.Sleep(50 * time.Millisecond)
+	if seat := activeSeat(playTable); seat != "bottom" {
+		t.Errorf("active seat %q after bouncing presses, want bottom", seat)
+	}
+}
+
+func TestInputPause(t *testing.T) {
+	leds, output, playTable := newTestTable(t)
+	defer leds.StopAnimation()
+	controller := NewInputController(leds, 0)
+	err := controller.Bind("gpio:17", "pause")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = controller.Handle(InputPress{Name: "gpio:17", Time: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !leds.IsPaused() || !leds.IsAnimationRunning() {
+		t.Fatalf("paused %v running %v, want paused and running", leds.IsPaused(), leds.IsAnimationRunning())
+	}
+	// frames keep being sent while paused
+	_, frames := output.GetFrame()
+	waitFor(t, "frames while paused", func() bool {
+		_, sent := output.GetFrame()
+		return sent > frames+2
+	})
+	if leds.GetCurrentAnimation() != playTable {
+		t.Error("animation replaced while paused")
+	}
+	err = controller.Handle(InputPress{Name: "gpio:17", Time: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leds.IsPaused() {
+		t.Error("still paused after second press")
+	}
+}
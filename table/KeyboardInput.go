@@ -0,0 +1,130 @@
+//go:build linux
+
+package table
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// linux/input.h
+const evKey = 0x01
+const evKeyPressed = 1
+const eviocgrab = 0x40044590
+const keyboardReopenDelay = 2 * time.Second
+
+// KeyboardInput reads key presses from a Linux evdev device, like
+// /dev/input/by-id/usb-...-event-kbd. If the device goes away, it is opened
+// again once it is back.
+type KeyboardInput struct {
+	device  string
+	grab    bool
+	presses chan InputPress
+	file    *os.File
+	closed  bool
+	lock    sync.Mutex
+}
+
+// NewKeyboardInput opens the device. With grab, key presses are not passed
+// on to other programs like the console.
+func NewKeyboardInput(device string, grab bool) (*KeyboardInput, error) {
+	input := new(KeyboardInput)
+	input.device = device
+	input.grab = grab
+	input.presses = make(chan InputPress, 16)
+	file, err := input.open()
+	if err != nil {
+		return nil, err
+	}
+	go input.read(file)
+	return input, nil
+}
+
+func (ki *KeyboardInput) open() (*os.File, error) {
+	file, err := os.Open(ki.device)
+	if err != nil {
+		return nil, err
+	}
+	if ki.grab {
+		_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, file.Fd(), eviocgrab, 1)
+		if errno != 0 {
+			file.Close()
+			return nil, fmt.Errorf("error grabbing %s: %v", ki.device, errno)
+		}
+	}
+	ki.lock.Lock()
+	defer ki.lock.Unlock()
+	if ki.closed {
+		file.Close()
+		return nil, fmt.Errorf("input %s closed", ki.device)
+	}
+	ki.file = file
+	return file, nil
+}
+
+func (ki *KeyboardInput) read(file *os.File) {
+	// struct input_event is a timeval followed by type, code and value
+	timevalSize := int(unsafe.Sizeof(syscall.Timeval{}))
+	event := make([]byte, timevalSize+8)
+	for {
+		_, err := io.ReadFull(file, event)
+		if err != nil {
+			file.Close()
+			file = ki.reopen()
+			if file == nil {
+				return
+			}
+			continue
+		}
+		eventType := binary.LittleEndian.Uint16(event[timevalSize:])
+		code := binary.LittleEndian.Uint16(event[timevalSize+2:])
+		value := int32(binary.LittleEndian.Uint32(event[timevalSize+4:]))
+		// ignore releases and auto repeat
+		if eventType != evKey || value != evKeyPressed {
+			continue
+		}
+		ki.presses <- InputPress{KeyInputName(ki.device, code), time.Now()}
+	}
+}
+
+// reopen waits for the device to come back. It returns nil once the input
+// has been closed.
+func (ki *KeyboardInput) reopen() *os.File {
+	fmt.Println("lost input device", ki.device)
+	for {
+		ki.lock.Lock()
+		closed := ki.closed
+		ki.lock.Unlock()
+		if closed {
+			return nil
+		}
+		time.Sleep(keyboardReopenDelay)
+		file, err := ki.open()
+		if err == nil {
+			fmt.Println("reopened input device", ki.device)
+			return file
+		}
+	}
+}
+
+// Presses returns the channel of presses.
+func (ki *KeyboardInput) Presses() <-chan InputPress {
+	return ki.presses
+}
+
+// Close closes the device.
+func (ki *KeyboardInput) Close() error {
+	ki.lock.Lock()
+	defer ki.lock.Unlock()
+	if ki.closed {
+		return nil
+	}
+	ki.closed = true
+	return ki.file.Close()
+}
@@ -0,0 +1,27 @@
+//go:build !linux
+
+package table
+
+import (
+	"errors"
+)
+
+// KeyboardInput is only supported on Linux.
+type KeyboardInput struct {
+	presses chan InputPress
+}
+
+// NewKeyboardInput fails, keyboard input needs Linux evdev devices.
+func NewKeyboardInput(device string, grab bool) (*KeyboardInput, error) {
+	return nil, errors.New("keyboard input is only supported on linux")
+}
+
+// Presses returns the channel of presses.
+func (ki *KeyboardInput) Presses() <-chan InputPress {
+	return ki.presses
+}
+
+// Close does nothing.
+func (ki *KeyboardInput) Close() error {
+	return nil
+}
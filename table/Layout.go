@@ -0,0 +1,137 @@
+package table
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DefaultLedCount is the number of LEDs of a table without a layout.
+const DefaultLedCount = 300
+
+// Layout describes the strip of a table: the number of LEDs, the range of
+// each seat and the corners between them. Seats are named like SeatNames,
+// a table may have fewer seats.
+type Layout struct {
+	LedCount int
+	Seats    map[string]Direction
+	Corners  map[string]Direction
+}
+
+// DefaultLayout is the layout of a table with the predefined Directions and
+// Corners.
+var DefaultLayout = Layout{DefaultLedCount, Directions, Corners}
+
+// NewLayout creates a layout from ranges like 0..40. Without seats, the
+// predefined Directions and Corners are used.
+func NewLayout(ledCount int, seats map[string]string, corners map[string]string) (Layout, error) {
+	if ledCount == 0 {
+		ledCount = DefaultLedCount
+	}
+	if ledCount < 0 {
+		return Layout{}, fmt.Errorf("invalid led count %d", ledCount)
+	}
+	layout := Layout{ledCount, map[string]Direction{}, map[string]Direction{}}
+	if len(seats) == 0 {
+		if len(corners) > 0 {
+			return Layout{}, fmt.Errorf("corners given without seats")
+		}
+		layout.Seats = Directions
+		layout.Corners = Corners
+		return layout, layout.check()
+	}
+	for name, encoded := range seats {
+		if !isSeatName(name) {
+			return Layout{}, fmt.Errorf("unknown seat %q, seats are %v", name, SeatNames)
+		}
+		direction, err := parseRange(encoded)
+		if err != nil {
+			return Layout{}, fmt.Errorf("seat %s: %v", name, err)
+		}
+		layout.Seats[name] = direction
+	}
+	for name, encoded := range corners {
+		if isSeatName(name) {
+			return Layout{}, fmt.Errorf("corner %q is named like a seat", name)
+		}
+		direction, err := parseRange(encoded)
+		if err != nil {
+			return Layout{}, fmt.Errorf("corner %s: %v", name, err)
+		}
+		layout.Corners[name] = direction
+	}
+	return layout, layout.check()
+}
+
+// check makes sure all ranges are on the strip and seats do not overlap.
+func (l Layout) check() error {
+	for name, direction := range l.Seats {
+		if direction.end > l.LedCount {
+			return fmt.Errorf("seat %s exceeds the %d leds", name, l.LedCount)
+		}
+	}
+	for name, direction := range l.Corners {
+		if direction.end > l.LedCount {
+			return fmt.Errorf("corner %s exceeds the %d leds", name, l.LedCount)
+		}
+	}
+	seats := l.SeatDirections()
+	sort.Slice(seats, func(i, j int) bool { return seats[i].start < seats[j].start })
+	for i := 1; i < len(seats); i++ {
+		if seats[i].start < seats[i-1].end {
+			return fmt.Errorf("seats %s and %s overlap", l.SeatName(seats[i-1]), l.SeatName(seats[i]))
+		}
+	}
+	return nil
+}
+
+// FrameSize returns the size of a frame for the layout in bytes.
+func (l Layout) FrameSize() int {
+	return l.LedCount * 3
+}
+
+// Seat returns the range of the named seat.
+func (l Layout) Seat(name string) (Direction, bool) {
+	direction, ok := l.Seats[name]
+	return direction, ok
+}
+
+// SeatName returns the name of the seat covering exactly the given range.
+func (l Layout) SeatName(direction Direction) string {
+	for _, name := range SeatNames {
+		if seat, ok := l.Seats[name]; ok && seat == direction {
+			return name
+		}
+	}
+	return ""
+}
+
+// SeatList returns the names of the seats of the layout in clockwise order.
+func (l Layout) SeatList() []string {
+	names := []string{}
+	for _, name := range SeatNames {
+		if _, ok := l.Seats[name]; ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// SeatDirections returns the seats of the layout in clockwise order.
+func (l Layout) SeatDirections() []Direction {
+	directions := []Direction{}
+	for _, name := range SeatNames {
+		if direction, ok := l.Seats[name]; ok {
+			directions = append(directions, direction)
+		}
+	}
+	return directions
+}
+
+func isSeatName(name string) bool {
+	for _, seat := range SeatNames {
+		if seat == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package table
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNewLayout(t *testing.T) {
+	tests := []struct {
+		name    string
+		leds    int
+		seats   map[string]string
+		corners map[string]string
+		valid   bool
+	}{
+		{"default", 0, nil, nil, true},
+		{"longer strip", 600, nil, nil, true},
+		{"strip shorter than the seats", 200, nil, nil, false},
+		{"own seats", 120, map[string]string{"right": "0..40", "left": "60..100"}, map[string]string{"between": "40..60"}, true},
+		{"unknown seat", 120, map[string]string{"kitchen": "0..40"}, nil, false},
+		{"overlapping seats", 120, map[string]string{"right": "0..40", "left": "30..60"}, nil, false},
+		{"seat beyond the strip", 120, map[string]string{"right": "100..130"}, nil, false},
+		{"invalid range", 120, map[string]string{"right": "40..0"}, nil, false},
+		{"corner named like a seat", 120, map[string]string{"right": "0..40"}, map[string]string{"left": "50..60"}, false},
+		{"corners without seats", 120, nil, map[string]string{"between": "40..60"}, false},
+		{"negative led count", -1, nil, nil, false},
+	}
+	for _, test := range tests {
+		_, err := NewLayout(test.leds, test.seats, test.corners)
+		if (err == nil) != test.valid {
+			t.Errorf("%s: error %v, want valid %v", test.name, err, test.valid)
+		}
+	}
+}
+
+func TestLayoutPlayTable(t *testing.T) {
+	layout, err := NewLayout(120, map[string]string{"right": "0..40", "bottom": "40..60", "top": "80..120"}, map[string]string{"gap": "60..80"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seats := fmt.Sprint(layout.SeatList()); seats != "[right bottom top]" {
+		t.Errorf("seats %s, want [right bottom top]", seats)
+	}
+	leds := NewLeds(NewFakeOutput(), layout.LedCount)
+	if len(*leds.GetFrameBuffer()) != 360 {
+		t.Fatalf("frame buffer of %d bytes, want 360", len(*leds.GetFrameBuffer()))
+	}
+	playTable := NewAnimationPlayTable(leds.GetFrameBuffer(), layout)
+	err = playTable.SetPlayerColorFromString("right=red;bottom=green;top=blue;gap=white")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = playTable.SetPlayerColorFromString("left=red"); err == nil {
+		t.Error("expected an error coloring a seat the table does not have")
+	}
+	if err = playTable.SetPlayerColorFromString("110..130=red"); err == nil {
+		t.Error("expected an error coloring a range beyond the strip")
+	}
+	// the turn passes the seats of the layout clockwise, left is missing
+	err = playTable.SetActiveDirection(layout.Seats["bottom"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = playTable.ActiveDirectionNext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seat := activeSeat(playTable); seat != "top" {
+		t.Errorf("active seat %q after bottom, want top", seat)
+	}
+	err = playTable.ActiveDirectionNext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seat := activeSeat(playTable); seat != "right" {
+		t.Errorf("active seat %q after top, want right", seat)
+	}
+	colormap := playTable.GetColormap().String()
+	if colormap != "right=#ff0000;bottom=#00ff00;top=#0000ff;60..80=#ffffff" {
+		t.Errorf("colormap %q", colormap)
+	}
+}
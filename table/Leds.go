@@ -0,0 +1,352 @@
+package table
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// consecutive failed frames before the output counts as disconnected
+const disconnectThreshold = 3
+
+// Leds runs animations and effects on an output device. lock guards the
+// state shared with the animation loop, outputLock the output, which is
+// used by the loop and by callers.
+type Leds struct {
+	output           Output
+	outputLock       sync.Mutex
+	lock             sync.Mutex
+	animationRunning bool
+	paused           bool
+	currentAnimation Animation
+	animationBuffer  []byte
+	frameBuffer      []byte
+	effectBuffer     []byte
+	effect           Effect
+	effectLock       sync.Mutex
+	brightness       byte
+	softBrightness   bool
+	brightnessBuffer []byte
+	failures         int
+	disconnected     bool
+	listeners        []EventListener
+}
+
+// NewLeds starts the animation loop for ledCount LEDs on output, which has
+// to be connected. Outputs needing more LEDs, like a virtual strip, get
+// frames of their size.
+func NewLeds(output Output, ledCount int) *Leds {
+	if counter, ok := output.(LedCounter); ok && counter.LedCount() > ledCount {
+		ledCount = counter.LedCount()
+	}
+	leds := new(Leds)
+	leds.output = output
+	leds.animationRunning = false
+	leds.brightness = 255
+	leds.animationBuffer = make([]byte, ledCount*3, ledCount*3)
+	leds.frameBuffer = make([]byte, ledCount*3, ledCount*3)
+	leds.effectBuffer = make([]byte, ledCount*3, ledCount*3)
+	leds.brightnessBuffer = make([]byte, ledCount*3, ledCount*3)
+	leds.startAnimationLoop()
+	return leds
+}
+
+// GetOutput returns the output device.
+func (leds *Leds) GetOutput() Output {
+	return leds.output
+}
+
+// Reconnect recreates the connection to the output. Animation setting is lost.
+func (leds *Leds) Reconnect(restartAnimation bool) error {
+	leds.lock.Lock()
+	if !restartAnimation && leds.animationRunning {
+		leds.currentAnimation = nil
+	}
+	leds.animationRunning = false
+	leds.lock.Unlock()
+	leds.outputLock.Lock()
+	err := leds.output.Close()
+	leds.outputLock.Unlock()
+	if err != nil {
+		return err
+	}
+	time.Sleep(1 * time.Second)
+	leds.outputLock.Lock()
+	err = leds.output.Connect()
+	leds.outputLock.Unlock()
+	if err != nil {
+		leds.setDisconnected(true)
+		return err
+	}
+	leds.lock.Lock()
+	leds.failures = 0
+	leds.lock.Unlock()
+	leds.setDisconnected(false)
+	leds.lock.Lock()
+	defer leds.lock.Unlock()
+	if restartAnimation && leds.currentAnimation != nil {
+		leds.animationRunning = true
+	}
+	return nil
+}
+
+// AddEventListener adds a listener for output disconnect and reconnect events.
+func (leds *Leds) AddEventListener(listener EventListener) {
+	leds.lock.Lock()
+	defer leds.lock.Unlock()
+	leds.listeners = append(leds.listeners, listener)
+}
+
+// setDisconnected records the connection state and emits an event when it
+// changes. The listeners are called without holding the lock.
+func (leds *Leds) setDisconnected(disconnected bool) {
+	leds.lock.Lock()
+	if disconnected == leds.disconnected {
+		leds.lock.Unlock()
+		return
+	}
+	leds.disconnected = disconnected
+	listeners := append([]EventListener{}, leds.listeners...)
+	leds.lock.Unlock()
+	event := NewEvent(EventControllerReconnected, map[string]interface{}{"host": leds.output.String()})
+	if disconnected {
+		event = NewEvent(EventControllerDisconnected, map[string]interface{}{"host": leds.output.String()})
+	}
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
+
+// GetFrameBuffer returns the frame buffer.
+func (leds *Leds) GetFrameBuffer() *[]byte {
+	return &leds.animationBuffer
+}
+
+func (leds *Leds) startAnimationLoop() {
+	go func() {
+		for {
+			leds.lock.Lock()
+			animation := leds.currentAnimation
+			step := leds.animationRunning && !leds.paused
+			running := leds.animationRunning && animation != nil
+			leds.lock.Unlock()
+			if running {
+				if step {
+					animation.Step()
+				}
+				frame := leds.renderEffect(leds.copyFrame(animation))
+				leds.sendFrame(leds.renderBrightness(frame))
+			}
+			// this is needed for the raspi
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+}
+
+// copyFrame copies the frame of the animation, locking it if the animation
+// paints its frame outside of Step.
+func (leds *Leds) copyFrame(animation Animation) []byte {
+	buffer := animation.GetFrameBuffer()
+	if locker, ok := animation.(FrameLocker); ok {
+		locker.LockFrame()
+		defer locker.UnlockFrame()
+	}
+	copy(leds.frameBuffer, *buffer)
+	return leds.frameBuffer
+}
+
+// sendFrame sends a frame and counts failures, the output counts as
+// disconnected after disconnectThreshold failed frames in a row.
+func (leds *Leds) sendFrame(frame []byte) {
+	leds.outputLock.Lock()
+	err := leds.output.SendFrame(frame)
+	leds.outputLock.Unlock()
+	leds.lock.Lock()
+	if err != nil {
+		leds.failures++
+		disconnected := leds.failures >= disconnectThreshold
+		leds.lock.Unlock()
+		fmt.Println("error rendering animation frame")
+		if disconnected {
+			leds.setDisconnected(true)
+		}
+		return
+	}
+	leds.failures = 0
+	leds.lock.Unlock()
+	leds.setDisconnected(false)
+}
+
+// renderEffect renders the running effect on top of a copy of the animation
+// frame, so the animation continues unchanged once the effect has finished.
+func (leds *Leds) renderEffect(frame []byte) []byte {
+	leds.effectLock.Lock()
+	effect := leds.effect
+	if effect == nil {
+		leds.effectLock.Unlock()
+		return frame
+	}
+	copy(leds.effectBuffer, frame)
+	if effect.Render(leds.effectBuffer) {
+		leds.effectLock.Unlock()
+		return leds.effectBuffer
+	}
+	leds.effect = nil
+	leds.effectLock.Unlock()
+	finishEffect(effect)
+	return leds.effectBuffer
+}
+
+// finishEffect tells an effect that it has ended.
+func finishEffect(effect Effect) {
+	if finishing, ok := effect.(FinishingEffect); ok {
+		finishing.Finish()
+	}
+}
+
+// renderBrightness scales the frame for outputs without a brightness setting.
+func (leds *Leds) renderBrightness(frame []byte) []byte {
+	leds.lock.Lock()
+	brightness := leds.brightness
+	soft := leds.softBrightness
+	leds.lock.Unlock()
+	if !soft || brightness == 255 {
+		return frame
+	}
+	for i, value := range frame {
+		leds.brightnessBuffer[i] = byte(int(value) * int(brightness) / 255)
+	}
+	return leds.brightnessBuffer
+}
+
+// PlayEffect plays a transient effect on top of the running animation.
+func (leds *Leds) PlayEffect(effect Effect) error {
+	if !leds.IsAnimationRunning() {
+		return errors.New("no animation running")
+	}
+	leds.effectLock.Lock()
+	replaced := leds.effect
+	leds.effect = effect
+	leds.effectLock.Unlock()
+	if replaced != nil {
+		finishEffect(replaced)
+	}
+	return nil
+}
+
+// StartAnimation starts a new animation.
+func (leds *Leds) StartAnimation(animation Animation) error {
+	if leds.animationBuffer == nil {
+		return errors.New("No framebuffer set for animation")
+	}
+	leds.outputLock.Lock()
+	err := leds.output.StartStream()
+	leds.outputLock.Unlock()
+	if err != nil {
+		return err
+	}
+	leds.lock.Lock()
+	defer leds.lock.Unlock()
+	// a new animation is not started frozen
+	if animation != leds.currentAnimation {
+		leds.paused = false
+	}
+	leds.currentAnimation = animation
+	leds.animationRunning = true
+	return nil
+}
+
+// StopAnimation stops an animation.
+func (leds *Leds) StopAnimation() error {
+	leds.lock.Lock()
+	running := leds.animationRunning
+	leds.animationRunning = false
+	leds.lock.Unlock()
+	if running {
+		time.Sleep(100 * time.Millisecond)
+	}
+	// this always succeeds, we ignore it if no animation is running
+	return nil
+}
+
+// Blank stops the animation and turns all LEDs off.
+func (leds *Leds) Blank() error {
+	leds.StopAnimation()
+	leds.outputLock.Lock()
+	defer leds.outputLock.Unlock()
+	err := leds.output.StartStream()
+	if err != nil {
+		return err
+	}
+	return leds.output.SendFrame(make([]byte, len(leds.animationBuffer)))
+}
+
+// SetPaused freezes or resumes the animation. A paused animation keeps
+// running and its frame keeps being sent, it only does not step.
+func (leds *Leds) SetPaused(paused bool) {
+	leds.lock.Lock()
+	defer leds.lock.Unlock()
+	leds.paused = paused
+}
+
+// IsPaused returns true if the animation is frozen.
+func (leds *Leds) IsPaused() bool {
+	leds.lock.Lock()
+	defer leds.lock.Unlock()
+	return leds.paused
+}
+
+// IsAnimationRunning returns true if an animation is running.
+func (leds *Leds) IsAnimationRunning() bool {
+	leds.lock.Lock()
+	defer leds.lock.Unlock()
+	return leds.animationRunning && leds.currentAnimation != nil
+}
+
+// GetCurrentAnimation returns the current Animation.
+func (leds *Leds) GetCurrentAnimation() Animation {
+	leds.lock.Lock()
+	defer leds.lock.Unlock()
+	return leds.currentAnimation
+}
+
+// SetBrightness sets the brightness. Outputs without a brightness setting
+// get frames scaled in software.
+func (leds *Leds) SetBrightness(value byte) error {
+	leds.lock.Lock()
+	leds.brightness = value
+	running := leds.animationRunning
+	animation := leds.currentAnimation
+	leds.lock.Unlock()
+	if running {
+		leds.StopAnimation()
+		err := leds.setOutputBrightness(value)
+		if err != nil {
+			return err
+		}
+		time.Sleep(100 * time.Millisecond)
+		return leds.StartAnimation(animation)
+	}
+	return leds.setOutputBrightness(value)
+}
+
+func (leds *Leds) setOutputBrightness(value byte) error {
+	leds.outputLock.Lock()
+	err := leds.output.SetBrightness(value)
+	leds.outputLock.Unlock()
+	if err == ErrBrightnessUnsupported {
+		leds.lock.Lock()
+		leds.softBrightness = true
+		leds.lock.Unlock()
+		return nil
+	}
+	return err
+}
+
+// GetBrightness returns the brightness last set.
+func (leds *Leds) GetBrightness() byte {
+	leds.lock.Lock()
+	defer leds.lock.Unlock()
+	return leds.brightness
+}
@@ -0,0 +1,188 @@
+package table
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// markerEffect paints the first pixel red for a number of frames.
+type markerEffect struct {
+	frames int
+}
+
+func (e *markerEffect) Render(frameBuffer []byte) bool {
+	if e.frames == 0 {
+		return false
+	}
+	e.frames--
+	copy(frameBuffer[0:3], []byte{255, 0, 0})
+	return true
+}
+
+// firstPixel returns the first pixel of the last frame sent.
+func firstPixel(output *FakeOutput) []byte {
+	frame, _ := output.GetFrame()
+	if len(frame) < 3 {
+		return nil
+	}
+	return frame[0:3]
+}
+
+func equalPixel(pixel []byte, r byte, g byte, b byte) bool {
+	return len(pixel) == 3 && pixel[0] == r && pixel[1] == g && pixel[2] == b
+}
+
+func TestLedsBrightness(t *testing.T) {
+	tests := []struct {
+		name      string
+		supported bool
+		value     byte
+		blue      byte
+		hardware  byte
+	}{
+		{"controller brightness", true, 128, 255, 128},
+		{"software brightness", false, 128, 128, 255},
+		{"software full brightness", false, 255, 255, 255},
+		{"software off", false, 0, 0, 255},
+	}
+	for _, test := range tests {
+		leds, output, _ := newTestTable(t)
+		output.SetBrightnessSupported(test.supported)
+		err := leds.SetBrightness(test.value)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		waitFor(t, test.name, func() bool { return equalPixel(firstPixel(output), 0, 0, test.blue) })
+		if output.GetBrightness() != test.hardware {
+			t.Errorf("%s: controller brightness %d, want %d", test.name, output.GetBrightness(), test.hardware)
+		}
+		if leds.GetBrightness() != test.value {
+			t.Errorf("%s: brightness %d, want %d", test.name, leds.GetBrightness(), test.value)
+		}
+		leds.StopAnimation()
+	}
+}
+
+func TestLedsEffectOverlay(t *testing.T) {
+	leds, output, playTable := newTestTable(t)
+	defer leds.StopAnimation()
+	waitFor(t, "animation frame", func() bool { return equalPixel(firstPixel(output), 0, 0, 255) })
+	err := leds.PlayEffect(&markerEffect{frames: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "effect frame", func() bool { return equalPixel(firstPixel(output), 255, 0, 0) })
+	// the effect is drawn on a copy, the animation keeps its frame
+	playTable.LockFrame()
+	buffer := append([]byte{}, (*leds.GetFrameBuffer())[0:3]...)
+	playTable.UnlockFrame()
+	if !equalPixel(buffer, 0, 0, 255) {
+		t.Errorf("animation frame buffer changed by effect: %v", buffer)
+	}
+	err = leds.PlayEffect(&markerEffect{frames: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "animation frame after effect", func() bool { return equalPixel(firstPixel(output), 0, 0, 255) })
+}
+
+func TestLedsEffectNeedsAnimation(t *testing.T) {
+	leds := NewLeds(NewFakeOutput(), DefaultLedCount)
+	if err := leds.PlayEffect(&markerEffect{frames: 1}); err == nil {
+		t.Error("expected an error playing an effect without animation")
+	}
+}
+
+func TestLedsDisconnectEvents(t *testing.T) {
+	leds, output, _ := newTestTable(t)
+	defer leds.StopAnimation()
+	var lock sync.Mutex
+	events := []string{}
+	leds.AddEventListener(func(event Event) {
+		lock.Lock()
+		defer lock.Unlock()
+		events = append(events, event.Type)
+	})
+	received := func() []string {
+		lock.Lock()
+		defer lock.Unlock()
+		return append([]string{}, events...)
+	}
+	output.SetFailing(true)
+	waitFor(t, "disconnect event", func() bool { return len(received()) == 1 })
+	if received()[0] != EventControllerDisconnected {
+		t.Errorf("event %q, want %q", received()[0], EventControllerDisconnected)
+	}
+	output.SetFailing(false)
+	waitFor(t, "reconnect event", func() bool { return len(received()) == 2 })
+	if received()[1] != EventControllerReconnected {
+		t.Errorf("event %q, want %q", received()[1], EventControllerReconnected)
+	}
+}
+
+func TestLedsDisconnectThreshold(t *testing.T) {
+	output := NewFakeOutput()
+	leds := NewLeds(output, DefaultLedCount)
+	events := 0
+	leds.AddEventListener(func(event Event) {
+		events++
+	})
+	output.SetFailing(true)
+	// frames are sent by the animation loop, count the failures directly
+	for i := 1; i <= disconnectThreshold; i++ {
+		leds.sendFrame(make([]byte, DefaultLedCount*3))
+		want := 0
+		if i == disconnectThreshold {
+			want = 1
+		}
+		if events != want {
+			t.Errorf("after %d failed frames %d events, want %d", i, events, want)
+		}
+	}
+}
+
+// countingAnimation counts its steps.
+type countingAnimation struct {
+	frameBuffer *[]byte
+	steps       int32
+}
+
+func (a *countingAnimation) Step() {
+	atomic.AddInt32(&a.steps, 1)
+}
+
+func (a *countingAnimation) SetFrameBuffer(frameBuffer *[]byte) {
+	a.frameBuffer = frameBuffer
+}
+
+func (a *countingAnimation) GetFrameBuffer() *[]byte {
+	return a.frameBuffer
+}
+
+func TestLedsPause(t *testing.T) {
+	output := NewFakeOutput()
+	leds := NewLeds(output, DefaultLedCount)
+	defer leds.StopAnimation()
+	animation := &countingAnimation{frameBuffer: leds.GetFrameBuffer()}
+	err := leds.StartAnimation(animation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "steps", func() bool { return atomic.LoadInt32(&animation.steps) > 0 })
+	leds.SetPaused(true)
+	// let a step in progress finish
+	time.Sleep(20 * time.Millisecond)
+	steps := atomic.LoadInt32(&animation.steps)
+	_, frames := output.GetFrame()
+	waitFor(t, "frames while paused", func() bool {
+		_, sent := output.GetFrame()
+		return sent > frames+2
+	})
+	if atomic.LoadInt32(&animation.steps) != steps {
+		t.Errorf("animation stepped while paused")
+	}
+	leds.SetPaused(false)
+	waitFor(t, "steps after resume", func() bool { return atomic.LoadInt32(&animation.steps) > steps })
+}
@@ -0,0 +1,140 @@
+package table
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// ErrBrightnessUnsupported is returned by outputs without a brightness
+// setting, the frames are scaled in software instead.
+var ErrBrightnessUnsupported = errors.New("brightness not supported by output")
+
+// Output is an LED controller animation frames are sent to. Frames hold
+// three bytes (RGB) per LED.
+type Output interface {
+	// Connect (re)establishes the connection.
+	Connect() error
+	// Close closes the connection.
+	Close() error
+	// StartStream prepares the controller to show the frames sent.
+	StartStream() error
+	// SendFrame shows a frame.
+	SendFrame(frame []byte) error
+	// SupportsBrightness tells whether the controller has a brightness setting.
+	SupportsBrightness() bool
+	// SetBrightness sets the brightness or returns ErrBrightnessUnsupported.
+	SetBrightness(value byte) error
+	// String describes the output for logs and events.
+	String() string
+}
+
+// LedCounter is implemented by outputs that need frames of a certain
+// number of LEDs, like virtual strips.
+type LedCounter interface {
+	LedCount() int
+}
+
+// OutputConfig selects and configures an output. Type is sp108e, wled,
+// ddp, e131, artnet, virtual or fake. A Port of 0 selects the default port
+// of the protocol, the sp108e Host "auto" the first controller discovered.
+// Universes are used by e131 (default 1) and artnet (default 0), segments
+// by virtual strips.
+type OutputConfig struct {
+	Type     string         `json:"type"`
+	Host     string         `json:"host,omitempty"`
+	Port     int            `json:"port,omitempty"`
+	Universe int            `json:"universe,omitempty"`
+	Segments []StripSegment `json:"segments,omitempty"`
+}
+
+// OutputTypes lists the supported output types.
+var OutputTypes = []string{"sp108e", "wled", "ddp", "e131", "artnet", "virtual", "fake"}
+
+// LoadOutputConfig reads an output configuration from a JSON file.
+func LoadOutputConfig(path string) (OutputConfig, error) {
+	config := OutputConfig{}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return config, fmt.Errorf("invalid output file %s: %v", path, err)
+	}
+	return config, nil
+}
+
+// NewOutput creates and connects the configured output.
+func NewOutput(config OutputConfig) (Output, error) {
+	port := config.Port
+	switch strings.ToLower(config.Type) {
+	case "", "sp108e":
+		if port == 0 {
+			port = Sp108eDefaultPort
+		}
+		return NewSp108e(config.Host, port)
+	case "wled":
+		return NewWled(config.Host, port)
+	case "ddp":
+		return NewDdp(config.Host, port)
+	case "e131", "sacn":
+		universe := config.Universe
+		if universe < 1 {
+			universe = 1
+		}
+		return NewE131(config.Host, port, universe)
+	case "artnet":
+		universe := config.Universe
+		if universe < 0 {
+			universe = 0
+		}
+		return NewArtNet(config.Host, port, universe)
+	case "virtual":
+		return NewVirtualStrip(config.Segments)
+	case "fake":
+		return NewFakeOutput(), nil
+	}
+	return nil, fmt.Errorf("unknown output type %q, supported are %s", config.Type, strings.Join(OutputTypes, ", "))
+}
+
+// udpOutput is the connection shared by the UDP based outputs.
+type udpOutput struct {
+	host       string
+	port       int
+	connection net.Conn
+}
+
+func (uo *udpOutput) Connect() error {
+	connection, err := net.Dial("udp", net.JoinHostPort(uo.host, strconv.Itoa(uo.port)))
+	if err != nil {
+		return err
+	}
+	uo.connection = connection
+	return nil
+}
+
+func (uo *udpOutput) Close() error {
+	if uo.connection == nil {
+		return nil
+	}
+	err := uo.connection.Close()
+	uo.connection = nil
+	return err
+}
+
+func (uo *udpOutput) send(packet []byte) error {
+	if uo.connection == nil {
+		return errors.New("connection closed")
+	}
+	_, err := uo.connection.Write(packet)
+	return err
+}
+
+func (uo *udpOutput) address() string {
+	return net.JoinHostPort(uo.host, strconv.Itoa(uo.port))
+}
@@ -0,0 +1,135 @@
+package table
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// PhaseEffect selects how the current phase is shown on the corners.
+type PhaseEffect string
+
+// Available phase effects.
+const (
+	PhaseSolid PhaseEffect = "solid"
+	PhasePulse PhaseEffect = "pulse"
+	PhaseBlink PhaseEffect = "blink"
+)
+
+const phasePulseDegreesPerStep = 3
+const phaseBlinkPeriodSteps = 50
+const flourishSteps = 150
+const flourishWidth = 30
+
+// Phase is a named game phase shown on the LEDs between the seats.
+type Phase struct {
+	Name   string
+	Fill   Fill
+	Effect PhaseEffect
+}
+
+// ParsePhase parses a phase given as name:fill[:effect].
+func ParsePhase(encoded string) (Phase, error) {
+	parts := strings.Split(encoded, ":")
+	if len(parts) < 2 || len(parts) > 3 || strings.TrimSpace(parts[0]) == "" {
+		return Phase{}, fmt.Errorf("invalid phase %q, expected name:fill[:effect]", encoded)
+	}
+	fill, err := ParseFill(parts[1])
+	if err != nil {
+		return Phase{}, fmt.Errorf("invalid phase %q: %v", encoded, err)
+	}
+	effect := PhaseSolid
+	if len(parts) == 3 {
+		effect = PhaseEffect(strings.TrimSpace(parts[2]))
+		if effect != PhaseSolid && effect != PhasePulse && effect != PhaseBlink {
+			return Phase{}, fmt.Errorf("invalid phase %q: unknown effect %q", encoded, effect)
+		}
+	}
+	return Phase{strings.TrimSpace(parts[0]), fill, effect}, nil
+}
+
+// SetPhases defines the phases of the session and starts with the first
+// phase of round 1. An empty list turns the phase tracker off.
+func (pt *AnimationPlayTable) SetPhases(phases []Phase) {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	pt.phases = append([]Phase{}, phases...)
+	pt.currentPhase = 0
+	pt.round = 1
+	pt.phaseStep = 0
+	if len(phases) == 0 {
+		// clear the corners again
+		if pt.frameBuffer != nil {
+			for _, corner := range pt.layout.Corners {
+				SolidFill(Color{}).paint(*pt.frameBuffer, corner)
+			}
+		}
+	}
+	pt.updateFrame()
+}
+
+// GetPhase returns the current phase and round.
+func (pt *AnimationPlayTable) GetPhase() (Phase, int, error) {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	if len(pt.phases) == 0 {
+		return Phase{}, 0, errors.New("no phases defined")
+	}
+	return pt.phases[pt.currentPhase], pt.round, nil
+}
+
+// PhaseNext advances to the next phase. When the list wraps, a new round
+// starts and a flourish is played; the returned flag reports the wrap.
+func (pt *AnimationPlayTable) PhaseNext() (bool, error) {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	if len(pt.phases) == 0 {
+		return false, errors.New("no phases defined")
+	}
+	pt.currentPhase++
+	pt.phaseStep = 0
+	wrapped := false
+	if pt.currentPhase == len(pt.phases) {
+		pt.currentPhase = 0
+		pt.round++
+		pt.flourishStep = flourishSteps
+		wrapped = true
+	}
+	return wrapped, pt.updateFrame()
+}
+
+// paintPhase renders the current phase onto the corners.
+func (pt *AnimationPlayTable) paintPhase() {
+	if len(pt.phases) == 0 {
+		return
+	}
+	phase := pt.phases[pt.currentPhase]
+	level := 1.0
+	switch phase.Effect {
+	case PhasePulse:
+		level = 0.5 + 0.5*math.Sin(float64(pt.phaseStep*phasePulseDegreesPerStep)*math.Pi/180)
+	case PhaseBlink:
+		if pt.phaseStep%phaseBlinkPeriodSteps >= phaseBlinkPeriodSteps/2 {
+			level = 0
+		}
+	}
+	for _, corner := range pt.layout.Corners {
+		phase.Fill.paint(*pt.frameBuffer, corner)
+		dimRange(*pt.frameBuffer, corner, level)
+	}
+}
+
+// paintFlourish renders the round-end flourish, a rainbow sweeping once
+// around the whole table.
+func (pt *AnimationPlayTable) paintFlourish() {
+	if pt.flourishStep <= 0 {
+		return
+	}
+	pixels := len(*pt.frameBuffer) / 3
+	head := (flourishSteps - pt.flourishStep) * (pixels + flourishWidth) / flourishSteps
+	for i := 0; i < flourishWidth; i++ {
+		hue := float64(i) * 360 / flourishWidth
+		blendPixel(*pt.frameBuffer, head-i, hsvToColor(hue, 1, 1), 1)
+	}
+}
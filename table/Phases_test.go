@@ -0,0 +1,130 @@
+package table
+
+import (
+	"testing"
+)
+
+func TestParsePhase(t *testing.T) {
+	tests := []struct {
+		encoded string
+		name    string
+		effect  PhaseEffect
+		valid   bool
+	}{
+		{"draw:red", "draw", PhaseSolid, true},
+		{" play :#00ff00:pulse", "play", PhasePulse, true},
+		{"score:blue:blink", "score", PhaseBlink, true},
+		{"draw", "", "", false},
+		{":red", "", "", false},
+		{"draw:nocolor", "", "", false},
+		{"draw:red:spin", "", "", false},
+		{"draw:red:pulse:extra", "", "", false},
+	}
+	for _, test := range tests {
+		phase, err := ParsePhase(test.encoded)
+		if (err == nil) != test.valid {
+			t.Errorf("%q: error %v, want valid %v", test.encoded, err, test.valid)
+			continue
+		}
+		if test.valid && (phase.Name != test.name || phase.Effect != test.effect) {
+			t.Errorf("%q: phase %q with %s, want %q with %s", test.encoded, phase.Name, phase.Effect, test.name, test.effect)
+		}
+	}
+}
+
+func TestPhaseNext(t *testing.T) {
+	leds := NewLeds(NewFakeOutput(), DefaultLedCount)
+	playTable := NewAnimationPlayTable(leds.GetFrameBuffer(), DefaultLayout)
+	frameBuffer := leds.GetFrameBuffer()
+	if _, err := playTable.PhaseNext(); err == nil {
+		t.Error("expected an error without phases")
+	}
+	playTable.SetPhases([]Phase{
+		{"draw", SolidFill(Colors["red"]), PhaseSolid},
+		{"play", SolidFill(Colors["green"]), PhaseSolid},
+	})
+	corner := Corners["bottomright"].start
+	if pixel := pixelAt(frameBuffer, corner); pixel != Colors["red"] {
+		t.Errorf("corner pixel %s in the first phase, want red", pixel.Hex())
+	}
+	tests := []struct {
+		phase   string
+		round   int
+		wrapped bool
+		color   Color
+	}{
+		{"play", 1, false, Colors["green"]},
+		{"draw", 2, true, Colors["red"]},
+		{"play", 2, false, Colors["green"]},
+	}
+	for _, test := range tests {
+		wrapped, err := playTable.PhaseNext()
+		if err != nil {
+			t.Fatal(err)
+		}
+		phase, round, err := playTable.GetPhase()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if phase.Name != test.phase || round != test.round || wrapped != test.wrapped {
+			t.Errorf("phase %s of round %d, wrapped %v, want %s of round %d, wrapped %v", phase.Name, round, wrapped, test.phase, test.round, test.wrapped)
+		}
+		if pixel := pixelAt(frameBuffer, corner); pixel != test.color {
+			t.Errorf("corner pixel %s in phase %s, want %s", pixel.Hex(), phase.Name, test.color.Hex())
+		}
+	}
+	playTable.SetPhases(nil)
+	if _, _, err := playTable.GetPhase(); err == nil {
+		t.Error("expected an error after turning the phases off")
+	}
+	if pixel := pixelAt(frameBuffer, corner); pixel != (Color{}) {
+		t.Errorf("corner pixel %s after turning the phases off", pixel.Hex())
+	}
+}
+
+func TestPhaseEffects(t *testing.T) {
+	leds := NewLeds(NewFakeOutput(), DefaultLedCount)
+	playTable := NewAnimationPlayTable(leds.GetFrameBuffer(), DefaultLayout)
+	frameBuffer := leds.GetFrameBuffer()
+	playTable.SetPhases([]Phase{
+		{"draw", SolidFill(Colors["white"]), PhaseBlink},
+		{"play", SolidFill(Colors["white"]), PhaseSolid},
+	})
+	err := playTable.SetPlayerColor(Directions["right"], Colors["blue"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	corner := Corners["topleft"].start
+	tests := []struct {
+		steps int
+		color Color
+	}{
+		{phaseBlinkPeriodSteps/2 - 1, Colors["white"]},
+		{1, Color{}},
+		{phaseBlinkPeriodSteps / 2, Colors["white"]},
+	}
+	for _, test := range tests {
+		for i := 0; i < test.steps; i++ {
+			playTable.Step()
+		}
+		if pixel := pixelAt(frameBuffer, corner); pixel != test.color {
+			t.Errorf("blinking corner pixel %s, want %s", pixel.Hex(), test.color.Hex())
+		}
+	}
+	// wrapping into a new round plays the flourish from the first pixel
+	for i := 0; i < 2; i++ {
+		if _, err = playTable.PhaseNext(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	playTable.Step()
+	if pixel := pixelAt(frameBuffer, 0); pixel != Colors["red"] {
+		t.Errorf("flourish head %s, want red", pixel.Hex())
+	}
+	for i := 0; i < flourishSteps; i++ {
+		playTable.Step()
+	}
+	if pixel := pixelAt(frameBuffer, 0); pixel != Colors["blue"] {
+		t.Errorf("pixel %s after the flourish, want the blue seat", pixel.Hex())
+	}
+}
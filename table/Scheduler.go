@@ -0,0 +1,278 @@
+package table
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const schedulerCheckInterval = 30 * time.Second
+
+// errNoSunEvent is returned for sun rules on days without sunrise or sunset.
+var errNoSunEvent = errors.New("no sunrise or sunset on this day")
+
+// ScheduleRule describes a time-based lighting rule. Time is either a clock
+// time like 23:00 or sunrise/sunset with an optional offset like sunset-30m.
+// Days lists weekdays (mon..sun), "weekdays" or "weekends", empty means every
+// day. Action is one of brightness (value in percent), ambient (value is an
+// ambient mode) or off.
+type ScheduleRule struct {
+	ID     string   `json:"id"`
+	Time   string   `json:"time"`
+	Days   []string `json:"days,omitempty"`
+	Action string   `json:"action"`
+	Value  string   `json:"value,omitempty"`
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Scheduler runs schedule rules against a controller and persists them.
+// Ambient and off rules go through the idle monitor, so the next use of the
+// table restores the animation shown before.
+type Scheduler struct {
+	leds        *Leds
+	idleMonitor *IdleMonitor
+	path        string
+	latitude    float64
+	longitude   float64
+	rules       []ScheduleRule
+	lastRun     map[string]string
+	lock        sync.Mutex
+}
+
+// NewScheduler loads the rules from path, if it exists, and starts the scheduler.
+func NewScheduler(leds *Leds, idleMonitor *IdleMonitor, path string, latitude float64, longitude float64) (*Scheduler, error) {
+	scheduler := new(Scheduler)
+	scheduler.leds = leds
+	scheduler.idleMonitor = idleMonitor
+	scheduler.path = path
+	scheduler.latitude = latitude
+	scheduler.longitude = longitude
+	scheduler.rules = []ScheduleRule{}
+	scheduler.lastRun = map[string]string{}
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &scheduler.rules)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule file %s: %v", path, err)
+		}
+		ids := map[string]bool{}
+		for _, rule := range scheduler.rules {
+			if ids[rule.ID] {
+				return nil, fmt.Errorf("duplicate rule id %q in %s", rule.ID, path)
+			}
+			ids[rule.ID] = true
+			err = scheduler.validate(rule)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rule %s in %s: %v", rule.ID, path, err)
+			}
+		}
+	}
+	// rules that have passed today already are not run on startup
+	now := time.Now()
+	for _, rule := range scheduler.rules {
+		if at, err := scheduler.triggerTime(rule, now); err == nil && !now.Before(at) {
+			scheduler.lastRun[rule.ID] = now.Format("2006-01-02")
+		}
+	}
+	go func() {
+		for {
+			time.Sleep(schedulerCheckInterval)
+			scheduler.check(time.Now())
+		}
+	}()
+	return scheduler, nil
+}
+
+// GetRules returns the schedule rules.
+func (s *Scheduler) GetRules() []ScheduleRule {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]ScheduleRule{}, s.rules...)
+}
+
+// AddRule validates, adds and persists a rule and returns its ID. The rule
+// is not added if it cannot be saved.
+func (s *Scheduler) AddRule(rule ScheduleRule) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	err := s.validate(rule)
+	if err != nil {
+		return "", err
+	}
+	id := 1
+	for _, existing := range s.rules {
+		if existingID, err := strconv.Atoi(existing.ID); err == nil && existingID >= id {
+			id = existingID + 1
+		}
+	}
+	rule.ID = strconv.Itoa(id)
+	now := time.Now()
+	if at, err := s.triggerTime(rule, now); err == nil && !now.Before(at) {
+		s.lastRun[rule.ID] = now.Format("2006-01-02")
+	}
+	s.rules = append(s.rules, rule)
+	err = s.save()
+	if err != nil {
+		s.rules = s.rules[:len(s.rules)-1]
+		delete(s.lastRun, rule.ID)
+		return "", err
+	}
+	return rule.ID, nil
+}
+
+// RemoveRule removes and persists a rule.
+func (s *Scheduler) RemoveRule(id string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for i, rule := range s.rules {
+		if rule.ID == id {
+			s.rules = append(s.rules[:i], s.rules[i+1:]...)
+			delete(s.lastRun, id)
+			return s.save()
+		}
+	}
+	return fmt.Errorf("unknown rule %q", id)
+}
+
+func (s *Scheduler) save() error {
+	data, err := json.MarshalIndent(s.rules, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.path, data, 0644)
+}
+
+func (s *Scheduler) validate(rule ScheduleRule) error {
+	_, err := s.triggerTime(rule, time.Now())
+	if err != nil && err != errNoSunEvent {
+		return err
+	}
+	for _, day := range rule.Days {
+		if _, ok := weekdayNames[day]; !ok && day != "weekdays" && day != "weekends" {
+			return fmt.Errorf("unknown day %q", day)
+		}
+	}
+	switch rule.Action {
+	case "brightness":
+		percent, err := strconv.Atoi(rule.Value)
+		if err != nil || percent < 0 || percent > 100 {
+			return fmt.Errorf("invalid brightness %q", rule.Value)
+		}
+	case "ambient":
+		if _, err := ParseAmbientMode(rule.Value); err != nil {
+			return err
+		}
+	case "off":
+	default:
+		return fmt.Errorf("unknown action %q", rule.Action)
+	}
+	return nil
+}
+
+// triggerTime returns when the rule triggers on the day of now.
+func (s *Scheduler) triggerTime(rule ScheduleRule, now time.Time) (time.Time, error) {
+	year, month, day := now.Date()
+	spec := rule.Time
+	for _, event := range []string{"sunrise", "sunset"} {
+		if !strings.HasPrefix(spec, event) {
+			continue
+		}
+		offset := time.Duration(0)
+		if rest := spec[len(event):]; rest != "" {
+			var err error
+			offset, err = time.ParseDuration(rest)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("invalid offset %q", rest)
+			}
+		}
+		sunrise, sunset, err := SunTimes(now, s.latitude, s.longitude)
+		if err != nil {
+			return time.Time{}, errNoSunEvent
+		}
+		if event == "sunrise" {
+			return sunrise.Add(offset), nil
+		}
+		return sunset.Add(offset), nil
+	}
+	clock, err := time.Parse("15:04", spec)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q", spec)
+	}
+	return time.Date(year, month, day, clock.Hour(), clock.Minute(), 0, 0, now.Location()), nil
+}
+
+func ruleAppliesOn(rule ScheduleRule, weekday time.Weekday) bool {
+	if len(rule.Days) == 0 {
+		return true
+	}
+	for _, day := range rule.Days {
+		switch day {
+		case "weekdays":
+			if weekday != time.Saturday && weekday != time.Sunday {
+				return true
+			}
+		case "weekends":
+			if weekday == time.Saturday || weekday == time.Sunday {
+				return true
+			}
+		default:
+			if weekdayNames[day] == weekday {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *Scheduler) check(now time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	today := now.Format("2006-01-02")
+	for _, rule := range s.rules {
+		if s.lastRun[rule.ID] == today || !ruleAppliesOn(rule, now.Weekday()) {
+			continue
+		}
+		at, err := s.triggerTime(rule, now)
+		if err != nil || now.Before(at) {
+			continue
+		}
+		s.lastRun[rule.ID] = today
+		fmt.Println("running scheduled rule", rule.ID, rule.Time, rule.Action, rule.Value)
+		err = s.run(rule)
+		if err != nil {
+			fmt.Println("error running scheduled rule:", err)
+		}
+	}
+}
+
+func (s *Scheduler) run(rule ScheduleRule) error {
+	switch rule.Action {
+	case "brightness":
+		percent, _ := strconv.Atoi(rule.Value)
+		return s.leds.SetBrightness(byte(percent * 255 / 100))
+	case "ambient":
+		mode, _ := ParseAmbientMode(rule.Value)
+		return s.idleMonitor.StartAmbient(mode)
+	case "off":
+		return s.idleMonitor.StartAmbient(AmbientOff)
+	}
+	return errors.New("unknown action")
+}
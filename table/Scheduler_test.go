@@ -0,0 +1,235 @@
+package table
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Berlin, the sun times are checked against the published ones.
+const testLatitude = 52.52
+const testLongitude = 13.405
+
+// newTestScheduler creates a scheduler storing its rules in a temporary
+// directory for the test table.
+func newTestScheduler(t *testing.T, leds *Leds, idleMonitor *IdleMonitor) *Scheduler {
+	scheduler, err := NewScheduler(leds, idleMonitor, filepath.Join(t.TempDir(), "schedule.json"), testLatitude, testLongitude)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return scheduler
+}
+
+func TestSunTimes(t *testing.T) {
+	tests := []struct {
+		name      string
+		date      time.Time
+		latitude  float64
+		longitude float64
+		sunrise   string
+		sunset    string
+		valid     bool
+	}{
+		{"berlin summer", time.Date(2024, 6, 21, 12, 0, 0, 0, time.UTC), testLatitude, testLongitude, "02:43", "19:33", true},
+		{"berlin winter", time.Date(2024, 12, 21, 12, 0, 0, 0, time.UTC), testLatitude, testLongitude, "07:15", "14:54", true},
+		{"equator", time.Date(2024, 3, 20, 12, 0, 0, 0, time.UTC), 0, 0, "06:04", "18:10", true},
+		{"polar day", time.Date(2024, 6, 21, 12, 0, 0, 0, time.UTC), 78.22, 15.65, "", "", false},
+		{"polar night", time.Date(2024, 12, 21, 12, 0, 0, 0, time.UTC), 78.22, 15.65, "", "", false},
+	}
+	for _, test := range tests {
+		sunrise, sunset, err := SunTimes(test.date, test.latitude, test.longitude)
+		if (err == nil) != test.valid {
+			t.Errorf("%s: error %v, want valid %v", test.name, err, test.valid)
+			continue
+		}
+		if !test.valid {
+			continue
+		}
+		for _, check := range []struct {
+			event string
+			at    time.Time
+			want  string
+		}{{"sunrise", sunrise, test.sunrise}, {"sunset", sunset, test.sunset}} {
+			clock, _ := time.Parse("15:04", check.want)
+			want := time.Date(2024, test.date.Month(), test.date.Day(), clock.Hour(), clock.Minute(), 0, 0, time.UTC)
+			if diff := check.at.Sub(want); diff < -3*time.Minute || diff > 3*time.Minute {
+				t.Errorf("%s: %s at %s, want %s", test.name, check.event, check.at.Format("15:04"), check.want)
+			}
+		}
+	}
+}
+
+func TestTriggerTime(t *testing.T) {
+	scheduler := &Scheduler{latitude: testLatitude, longitude: testLongitude}
+	day := time.Date(2024, 6, 21, 12, 0, 0, 0, time.UTC)
+	sunrise, sunset, err := SunTimes(day, testLatitude, testLongitude)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		time  string
+		want  time.Time
+		valid bool
+	}{
+		{"23:00", time.Date(2024, 6, 21, 23, 0, 0, 0, time.UTC), true},
+		{"07:30", time.Date(2024, 6, 21, 7, 30, 0, 0, time.UTC), true},
+		{"sunrise", sunrise, true},
+		{"sunset", sunset, true},
+		{"sunset-30m", sunset.Add(-30 * time.Minute), true},
+		{"sunrise+1h15m", sunrise.Add(75 * time.Minute), true},
+		{"sunset-soon", time.Time{}, false},
+		{"25:00", time.Time{}, false},
+		{"noon", time.Time{}, false},
+		{"", time.Time{}, false},
+	}
+	for _, test := range tests {
+		at, err := scheduler.triggerTime(ScheduleRule{Time: test.time}, day)
+		if (err == nil) != test.valid {
+			t.Errorf("%q: error %v, want valid %v", test.time, err, test.valid)
+			continue
+		}
+		if test.valid && !at.Equal(test.want) {
+			t.Errorf("%q: triggers at %v, want %v", test.time, at, test.want)
+		}
+	}
+	polar := &Scheduler{latitude: 78.22, longitude: 15.65}
+	if _, err := polar.triggerTime(ScheduleRule{Time: "sunset"}, day); err != errNoSunEvent {
+		t.Errorf("sunset during the polar day: error %v, want %v", err, errNoSunEvent)
+	}
+}
+
+func TestRuleAppliesOn(t *testing.T) {
+	tests := []struct {
+		days    []string
+		weekday time.Weekday
+		applies bool
+	}{
+		{nil, time.Monday, true},
+		{nil, time.Sunday, true},
+		{[]string{"mon"}, time.Monday, true},
+		{[]string{"mon"}, time.Tuesday, false},
+		{[]string{"sat", "sun"}, time.Sunday, true},
+		{[]string{"weekdays"}, time.Friday, true},
+		{[]string{"weekdays"}, time.Saturday, false},
+		{[]string{"weekends"}, time.Saturday, true},
+		{[]string{"weekends"}, time.Wednesday, false},
+		{[]string{"weekends", "wed"}, time.Wednesday, true},
+	}
+	for _, test := range tests {
+		if applies := ruleAppliesOn(ScheduleRule{Days: test.days}, test.weekday); applies != test.applies {
+			t.Errorf("%v on %v: applies %v, want %v", test.days, test.weekday, applies, test.applies)
+		}
+	}
+}
+
+func TestSchedulerValidate(t *testing.T) {
+	scheduler := &Scheduler{latitude: testLatitude, longitude: testLongitude}
+	tests := []struct {
+		rule  ScheduleRule
+		valid bool
+	}{
+		{ScheduleRule{Time: "23:00", Action: "off"}, true},
+		{ScheduleRule{Time: "sunset", Action: "ambient", Value: "warmwhite"}, true},
+		{ScheduleRule{Time: "08:00", Days: []string{"weekdays"}, Action: "brightness", Value: "50"}, true},
+		{ScheduleRule{Time: "08:00", Action: "brightness", Value: "101"}, false},
+		{ScheduleRule{Time: "08:00", Action: "brightness", Value: "half"}, false},
+		{ScheduleRule{Time: "08:00", Action: "ambient", Value: "disco"}, false},
+		{ScheduleRule{Time: "08:00", Action: "dance"}, false},
+		{ScheduleRule{Time: "08:00", Days: []string{"someday"}, Action: "off"}, false},
+		{ScheduleRule{Time: "8 pm", Action: "off"}, false},
+	}
+	for _, test := range tests {
+		err := scheduler.validate(test.rule)
+		if (err == nil) != test.valid {
+			t.Errorf("%+v: error %v, want valid %v", test.rule, err, test.valid)
+		}
+	}
+}
+
+func TestSchedulerRules(t *testing.T) {
+	leds, _, _ := newTestTable(t)
+	scheduler := newTestScheduler(t, leds, NewIdleMonitor(leds, 0, AmbientBreathing))
+	id, err := scheduler.AddRule(ScheduleRule{Time: "23:00", Action: "off"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := scheduler.AddRule(ScheduleRule{Time: "sunset", Action: "ambient", Value: "warmwhite"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "1" || second != "2" {
+		t.Errorf("rule ids %s and %s, want 1 and 2", id, second)
+	}
+	// the rules are persisted and loaded again
+	loaded, err := NewScheduler(leds, nil, scheduler.path, testLatitude, testLongitude)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules := loaded.GetRules(); len(rules) != 2 || rules[1].Value != "warmwhite" {
+		t.Errorf("loaded rules %+v", rules)
+	}
+	err = scheduler.RemoveRule(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = scheduler.RemoveRule(id); err == nil {
+		t.Error("expected an error removing an unknown rule")
+	}
+	if rules := scheduler.GetRules(); len(rules) != 1 || rules[0].ID != second {
+		t.Errorf("rules %+v after removing %s", rules, id)
+	}
+}
+
+func TestSchedulerAddRuleNotSaved(t *testing.T) {
+	leds, _, _ := newTestTable(t)
+	path := filepath.Join(t.TempDir(), "missing", "schedule.json")
+	scheduler, err := NewScheduler(leds, nil, path, testLatitude, testLongitude)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = scheduler.AddRule(ScheduleRule{Time: "23:00", Action: "off"}); err == nil {
+		t.Fatal("expected an error saving to a missing directory")
+	}
+	if rules := scheduler.GetRules(); len(rules) != 0 {
+		t.Errorf("rules %+v kept after the save failed", rules)
+	}
+	if len(scheduler.lastRun) != 0 {
+		t.Errorf("last runs %v kept after the save failed", scheduler.lastRun)
+	}
+}
+
+func TestSchedulerDuplicateIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedule.json")
+	rules := `[{"id": "1", "time": "23:00", "action": "off"}, {"id": "1", "time": "07:00", "action": "brightness", "value": "80"}]`
+	err := ioutil.WriteFile(path, []byte(rules), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = NewScheduler(nil, nil, path, testLatitude, testLongitude); err == nil {
+		t.Error("expected an error loading duplicate rule ids")
+	}
+}
+
+func TestSchedulerRestoresPlayTable(t *testing.T) {
+	for _, rule := range []ScheduleRule{{Time: "23:00", Action: "off"}, {Time: "23:00", Action: "ambient", Value: "colorcycle"}} {
+		leds, _, playTable := newTestTable(t)
+		idleMonitor := NewIdleMonitor(leds, 0, AmbientBreathing)
+		scheduler := newTestScheduler(t, leds, idleMonitor)
+		err := scheduler.run(rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := leds.GetCurrentAnimation().(*AnimationAmbient); !ok {
+			t.Fatalf("%s: animation %T, want the ambient animation", rule.Action, leds.GetCurrentAnimation())
+		}
+		err = idleMonitor.Touch()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if leds.GetCurrentAnimation() != Animation(playTable) {
+			t.Errorf("%s: animation %T after the next use, want the play table", rule.Action, leds.GetCurrentAnimation())
+		}
+		leds.StopAnimation()
+	}
+}
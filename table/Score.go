@@ -0,0 +1,100 @@
+package table
+
+import (
+	"errors"
+)
+
+// ScoreEvent reports what changed when a score was set.
+type ScoreEvent string
+
+// Score events.
+const (
+	ScoreNoEvent       ScoreEvent = ""
+	ScoreLeadTaken     ScoreEvent = "lead"
+	ScoreTargetReached ScoreEvent = "target"
+)
+
+const scoreBackgroundLevel = 0.05
+
+// SetScoreTarget enables the score mode, in which every seat shows its score
+// as a bar relative to target. A target of 0 turns the score mode off.
+func (pt *AnimationPlayTable) SetScoreTarget(target int) error {
+	if target < 0 {
+		return errors.New("negative score target")
+	}
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	pt.scoreTarget = target
+	if target == 0 {
+		pt.scores = map[Direction]int{}
+		pt.scoreLeader = nil
+	}
+	return pt.updateFrame()
+}
+
+// GetScoreTarget returns the score target, 0 if the score mode is off.
+func (pt *AnimationPlayTable) GetScoreTarget() int {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	return pt.scoreTarget
+}
+
+// SetScore sets the score of a seat and reports if the seat has taken the
+// lead or reached the target with it.
+func (pt *AnimationPlayTable) SetScore(direction Direction, score int) (ScoreEvent, error) {
+	direction, err := pt.checkDirection(direction)
+	if err != nil {
+		return ScoreNoEvent, err
+	}
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	if pt.scoreTarget == 0 {
+		return ScoreNoEvent, errors.New("score mode not enabled")
+	}
+	if score < 0 {
+		return ScoreNoEvent, errors.New("negative score")
+	}
+	previous := pt.scores[direction]
+	pt.scores[direction] = score
+	event := ScoreNoEvent
+	if previous < pt.scoreTarget && score >= pt.scoreTarget {
+		event = ScoreTargetReached
+	}
+	leader := true
+	for other, otherScore := range pt.scores {
+		if other != direction && otherScore >= score {
+			leader = false
+		}
+	}
+	if leader && score > 0 && (pt.scoreLeader == nil || *pt.scoreLeader != direction) {
+		pt.scoreLeader = &direction
+		if event == ScoreNoEvent {
+			event = ScoreLeadTaken
+		}
+	}
+	return event, pt.updateFrame()
+}
+
+// GetScores returns the scores of all seats.
+func (pt *AnimationPlayTable) GetScores() map[Direction]int {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	scores := map[Direction]int{}
+	for direction, score := range pt.scores {
+		scores[direction] = score
+	}
+	return scores
+}
+
+// paintScore turns the seat into a bar showing its score.
+func (pt *AnimationPlayTable) paintScore(direction Direction) {
+	if pt.scoreTarget == 0 {
+		return
+	}
+	length := direction.end - direction.start
+	lit := pt.scores[direction] * length / pt.scoreTarget
+	if lit >= length {
+		return
+	}
+	dimRange(*pt.frameBuffer, Direction{direction.start + lit, direction.end}, scoreBackgroundLevel)
+}
@@ -0,0 +1,91 @@
+package table
+
+import (
+	"testing"
+)
+
+func TestScoreEvents(t *testing.T) {
+	_, _, playTable := newTestTable(t)
+	if _, err := playTable.SetScore(Directions["right"], 1); err == nil {
+		t.Error("expected an error without score mode")
+	}
+	if err := playTable.SetScoreTarget(-1); err == nil {
+		t.Error("expected an error with a negative target")
+	}
+	err := playTable.SetScoreTarget(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		seat  string
+		score int
+		event ScoreEvent
+	}{
+		{"right", 0, ScoreNoEvent},
+		{"right", 3, ScoreLeadTaken},
+		{"right", 4, ScoreNoEvent},
+		// a tie does not take the lead
+		{"left", 4, ScoreNoEvent},
+		{"left", 6, ScoreLeadTaken},
+		{"right", 10, ScoreTargetReached},
+		{"right", 12, ScoreNoEvent},
+		{"left", 13, ScoreTargetReached},
+	}
+	for _, test := range tests {
+		event, err := playTable.SetScore(Directions[test.seat], test.score)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event != test.event {
+			t.Errorf("%s scores %d: event %q, want %q", test.seat, test.score, event, test.event)
+		}
+	}
+	if _, err = playTable.SetScore(Directions["right"], -1); err == nil {
+		t.Error("expected an error with a negative score")
+	}
+	if scores := playTable.GetScores(); scores[Directions["right"]] != 12 || scores[Directions["left"]] != 13 {
+		t.Errorf("scores %v", scores)
+	}
+	err = playTable.SetScoreTarget(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scores := playTable.GetScores(); len(scores) != 0 {
+		t.Errorf("scores %v after turning the score mode off", scores)
+	}
+}
+
+func TestScoreBar(t *testing.T) {
+	leds := NewLeds(NewFakeOutput(), DefaultLedCount)
+	playTable := NewAnimationPlayTable(leds.GetFrameBuffer(), DefaultLayout)
+	frameBuffer := leds.GetFrameBuffer()
+	err := playTable.SetPlayerColor(Directions["right"], Colors["white"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = playTable.SetScoreTarget(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dimmed := Color{12, 12, 12}
+	tests := []struct {
+		score  int
+		pixels map[int]Color
+	}{
+		{0, map[int]Color{0: dimmed, 39: dimmed}},
+		// half the target lights half of the 40 pixel seat
+		{5, map[int]Color{0: Colors["white"], 19: Colors["white"], 20: dimmed}},
+		{15, map[int]Color{0: Colors["white"], 39: Colors["white"]}},
+	}
+	for _, test := range tests {
+		_, err = playTable.SetScore(Directions["right"], test.score)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for index, want := range test.pixels {
+			if pixel := pixelAt(frameBuffer, index); pixel != want {
+				t.Errorf("score %d: pixel %d is %s, want %s", test.score, index, pixel.Hex(), want.Hex())
+			}
+		}
+	}
+}
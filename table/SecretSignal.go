@@ -0,0 +1,60 @@
+package table
+
+import (
+	"errors"
+	"time"
+)
+
+const maxSecretDuration = 60 * time.Second
+
+// SecretSignal is a color shown privately on a single seat for a while.
+type SecretSignal struct {
+	Seat     Direction
+	Color    Color
+	Duration time.Duration
+}
+
+// secretSignalEffect blanks the table and shows the signals on their seats
+// until the longest one has expired. The signals only live in the effect, so
+// they never show up in the state of the animation.
+type secretSignalEffect struct {
+	signals []SecretSignal
+	started time.Time
+}
+
+// NewSecretSignalEffect creates an effect showing secret signals.
+func NewSecretSignalEffect(signals []SecretSignal) (Effect, error) {
+	if len(signals) == 0 {
+		return nil, errors.New("no signals given")
+	}
+	for _, signal := range signals {
+		if signal.Duration <= 0 || signal.Duration > maxSecretDuration {
+			return nil, errors.New("signal duration out of range")
+		}
+	}
+	return &secretSignalEffect{signals: append([]SecretSignal{}, signals...)}, nil
+}
+
+// Render implements Effect.
+func (e *secretSignalEffect) Render(frameBuffer []byte) bool {
+	if e.started.IsZero() {
+		e.started = time.Now()
+	}
+	elapsed := time.Since(e.started)
+	// keep the table neutral, so nobody can tell which seats are signaled
+	for i := range frameBuffer {
+		frameBuffer[i] = 0
+	}
+	running := false
+	for _, signal := range e.signals {
+		if elapsed < signal.Duration {
+			SolidFill(signal.Color).paint(frameBuffer, signal.Seat)
+			running = true
+		}
+	}
+	if !running {
+		// clear the signal data once it is no longer needed
+		e.signals = nil
+	}
+	return running
+}
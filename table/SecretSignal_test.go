@@ -0,0 +1,75 @@
+package table
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewSecretSignalEffect(t *testing.T) {
+	tests := []struct {
+		name    string
+		signals []SecretSignal
+		valid   bool
+	}{
+		{"one signal", []SecretSignal{{Directions["left"], Colors["red"], time.Second}}, true},
+		{"longest duration", []SecretSignal{{Directions["left"], Colors["red"], maxSecretDuration}}, true},
+		{"no signals", nil, false},
+		{"no duration", []SecretSignal{{Directions["left"], Colors["red"], 0}}, false},
+		{"too long", []SecretSignal{{Directions["left"], Colors["red"], maxSecretDuration + time.Second}}, false},
+	}
+	for _, test := range tests {
+		if _, err := NewSecretSignalEffect(test.signals); (err == nil) != test.valid {
+			t.Errorf("%s: error %v, want valid %v", test.name, err, test.valid)
+		}
+	}
+}
+
+func TestSecretSignalEffect(t *testing.T) {
+	effect, err := NewSecretSignalEffect([]SecretSignal{
+		{Directions["left"], Colors["red"], time.Second},
+		{Directions["top"], Colors["green"], 20 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame := make([]byte, DefaultLedCount*3)
+	for i := range frame {
+		frame[i] = 255
+	}
+	if !effect.Render(frame) {
+		t.Fatal("effect finished on the first frame")
+	}
+	tests := []struct {
+		index int
+		color Color
+	}{
+		{Directions["left"].start, Colors["red"]},
+		{Directions["top"].start, Colors["green"]},
+		// the rest of the table is blanked
+		{Directions["right"].start, Color{}},
+	}
+	for _, test := range tests {
+		if pixel := pixelAt(&frame, test.index); pixel != test.color {
+			t.Errorf("pixel %d is %s, want %s", test.index, pixel.Hex(), test.color.Hex())
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+	if !effect.Render(frame) {
+		t.Fatal("effect finished before the longest signal")
+	}
+	if pixel := pixelAt(&frame, Directions["top"].start); pixel != (Color{}) {
+		t.Errorf("expired signal pixel %s, want off", pixel.Hex())
+	}
+	if pixel := pixelAt(&frame, Directions["left"].start); pixel != Colors["red"] {
+		t.Errorf("running signal pixel %s, want red", pixel.Hex())
+	}
+	effect, err = NewSecretSignalEffect([]SecretSignal{{Directions["top"], Colors["green"], 20 * time.Millisecond}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	effect.Render(frame)
+	time.Sleep(50 * time.Millisecond)
+	if effect.Render(frame) {
+		t.Error("effect still running after all signals expired")
+	}
+}
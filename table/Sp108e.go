@@ -3,10 +3,10 @@ package table
 import (
 	"io"
 	"fmt"
-	"time"
 	"errors"
 	"strconv"
 	"net"
+	"time"
 )
 
 const cmdFrameStart = 0x38
@@ -14,73 +14,88 @@ const cmdFrameEnd = 0x83
 
 const cmdCustomPreview = 0x24
 const cmdBrightness = 0x2a
+const cmdGetStatus = 0x10
+const cmdGetName = 0x77
+
+// Sp108eDefaultPort is the TCP port of the SP108E.
+const Sp108eDefaultPort = 8189
 
 // Sp108e represents the connection to an SP108E.
 type Sp108e struct {
 	ip string
 	port int
+	auto bool
 	connection net.Conn
-	animationRunning bool
-	currentAnimation Animation
- 	animationBuffer []byte
 }
 
-// NewSp108e returns a new connection.
+// NewSp108e returns a new connection. The ip "auto" selects the first
+// controller discovered, it is discovered again when connecting fails.
 func NewSp108e(ip string, port int) (*Sp108e, error) {
 	leds := new(Sp108e)
-	leds.ip = ip
 	leds.port = port
-	leds.animationRunning = false
-	leds.animationBuffer = make([]byte, 900, 900)
+	if ip == "auto" {
+		leds.auto = true
+	} else {
+		leds.ip = ip
+	}
 	var err error
-	err = leds.CreateConnection()
+	err = leds.Connect()
 	if err != nil {
 		return nil, err
 	}
-	leds.startAnimationLoop()
 	return leds, nil
 }
 
-// CreateConnection creates a connection.
-func (leds *Sp108e) CreateConnection() error {
+// String returns the address of the controller.
+func (leds *Sp108e) String() string {
+	if leds.ip == "" {
+		return "auto:" + strconv.Itoa(leds.port)
+	}
+	return leds.ip + ":" + strconv.Itoa(leds.port)
+}
+
+// Connect creates a connection. With auto selection, the controller is
+// discovered again if it cannot be reached, e.g. after it got a new address.
+func (leds *Sp108e) Connect() error {
 	var err error
 	fmt.Println("establishing connection")
+	if leds.ip != "" {
+		leds.connection, err = net.Dial("tcp", leds.ip + ":" + strconv.Itoa(leds.port))
+		if err == nil {
+			return nil
+		}
+	}
+	leds.connection = nil
+	if !leds.auto {
+		return err
+	}
+	ip, discoverErr := AutoSelectSp108e(leds.port)
+	if discoverErr != nil {
+		if err != nil {
+			return err
+		}
+		return discoverErr
+	}
+	leds.ip = ip
 	leds.connection, err = net.Dial("tcp", leds.ip + ":" + strconv.Itoa(leds.port))
 	if err != nil {
+		leds.connection = nil
 		return err
 	}
 	return nil
 }
 
-// CloseConnection creates a connection.
-func (leds *Sp108e) CloseConnection() error {
+// Close closes the connection.
+func (leds *Sp108e) Close() error {
 	var err error
 	fmt.Println("closing connection")
-	err = leds.connection.Close()
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-// Reconnect recreates a connection. Animation setting is lost.
-func (leds *Sp108e) Reconnect(restartAnimation bool) error {
-	leds.animationRunning = false
-	if !restartAnimation && leds.animationRunning {
-		leds.currentAnimation = nil
-	}
-	err := leds.CloseConnection()
-	if err != nil {
-		return err
+	if leds.connection == nil {
+		return nil
 	}
-	time.Sleep(1 * time.Second)
-	err = leds.CreateConnection()
+	err = leds.connection.Close()
 	if err != nil {
 		return err
 	}
-	if restartAnimation && leds.currentAnimation != nil {
-		leds.animationRunning = true
-	}
 	return nil
 }
 
@@ -95,16 +110,11 @@ func (leds *Sp108e) IsConnectionEstablished() bool {
 	if _, err := leds.connection.Read(one); err == io.EOF {
 		fmt.Println("detected closed connection")
 		leds.connection.Close()
-		leds.CreateConnection()
+		return leds.Connect() == nil
 	}
 	return true
 }
 
-// GetFrameBuffer returns the frame buffer.
-func (leds *Sp108e) GetFrameBuffer() *[]byte {
-	return &leds.animationBuffer
-}
-
 func (leds *Sp108e) createCommandPacket(command byte, frame []byte) ([]byte, error) {
 	if len(frame)!=3 {
 		return nil, errors.New("command frame is not 3 bytes")
@@ -122,6 +132,7 @@ func (leds *Sp108e) sendCommand(command []byte, confirmExpected bool) error {
 	// the timeout is established by the check method
 	if !leds.IsConnectionEstablished() {
 		fmt.Println("connection closed, not sending command")
+		return errors.New("connection closed")
 	}
 	leds.connection.Write(command)
 	//time.Sleep(10 * time.Millisecond)
@@ -136,63 +147,45 @@ func (leds *Sp108e) sendCommand(command []byte, confirmExpected bool) error {
 	return nil
 }
 
-func (leds *Sp108e) startAnimationLoop() {
-	go func() {
-		for {
-			if (leds.animationRunning && leds.currentAnimation != nil) {
-				(leds.currentAnimation).Step()
-				err := leds.sendCommand(*(leds.currentAnimation.GetFrameBuffer()), true)
-				if err != nil {
-					fmt.Println("error rendering animation frame")
-				}
-			}
-			// this is needed for the raspi
-			time.Sleep(10 * time.Millisecond)
-		}
-	}()
+// query sends a command and returns the response read within timeout.
+func (leds *Sp108e) query(command byte, timeout time.Duration) ([]byte, error) {
+	if leds.connection == nil {
+		return nil, errors.New("connection closed")
+	}
+	packet, _ := leds.createCommandPacket(command, []byte {0x0, 0x0, 0x0})
+	leds.connection.SetDeadline(time.Now().Add(timeout))
+	defer leds.connection.SetDeadline(time.Time{})
+	_, err := leds.connection.Write(packet)
+	if err != nil {
+		return nil, err
+	}
+	response := make([]byte, 64)
+	n, err := leds.connection.Read(response)
+	if err != nil {
+		return nil, err
+	}
+	return response[:n], nil
 }
 
-// StartAnimation starts a new animation.
-func (leds *Sp108e) StartAnimation(animation Animation) error {
-	if leds.animationBuffer == nil {
-		return errors.New("No framebuffer set for animation")
-	}
+// StartStream switches the controller to the custom preview mode, which
+// shows the frames sent.
+func (leds *Sp108e) StartStream() error {
 	command, _ := leds.createCommandPacket(cmdCustomPreview, []byte {0x0, 0x0, 0x0})
-	err := leds.sendCommand(command, true)
-	if err != nil {
-		return err
-	} 
-	leds.currentAnimation = animation
-	leds.animationRunning = true
-	return nil
+	return leds.sendCommand(command, true)
 }
 
-// StopAnimation stops an animation.
-func (leds *Sp108e) StopAnimation() error {
-	if leds.animationRunning {
-		leds.animationRunning = false
-		time.Sleep(100 * time.Millisecond)
-	}
-	// this always succeeds, we ignore it if no animation is running
-	return nil
+// SendFrame sends a frame in custom preview mode.
+func (leds *Sp108e) SendFrame(frame []byte) error {
+	return leds.sendCommand(frame, true)
 }
 
-// GetCurrentAnimation returns the current Animation.
-func (leds *Sp108e) GetCurrentAnimation() Animation {
-	return leds.currentAnimation
+// SupportsBrightness returns true, the controller has a brightness setting.
+func (leds *Sp108e) SupportsBrightness() bool {
+	return true
 }
 
-// SetBrightness sets the brightness.
+// SetBrightness sets the brightness of the controller.
 func (leds *Sp108e) SetBrightness(value byte) error {
 	command, _ := leds.createCommandPacket(cmdBrightness, []byte {byte(value), byte(value), byte(value)})
-	if leds.animationRunning {
-		leds.StopAnimation();
-		err := leds.sendCommand(command, false)
-		if err != nil {
-			return err
-		} 
-		time.Sleep(100 * time.Millisecond)
-		return leds.StartAnimation(leds.currentAnimation)
-	}
 	return leds.sendCommand(command, false)
 }
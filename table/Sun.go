@@ -0,0 +1,33 @@
+package table
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// SunTimes computes sunrise and sunset for the day of date at the given
+// coordinates, following the NOAA sunrise equation. The returned times are
+// in the location of date.
+func SunTimes(date time.Time, latitude float64, longitude float64) (time.Time, time.Time, error) {
+	rad := math.Pi / 180
+	// days since J2000.0
+	year, month, day := date.Date()
+	midnight := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	n := math.Ceil(float64(midnight.Unix())/86400+2440587.5-2451545.0+0.0008) - longitude/360
+	meanAnomaly := math.Mod(357.5291+0.98560028*n, 360)
+	center := 1.9148*math.Sin(meanAnomaly*rad) + 0.0200*math.Sin(2*meanAnomaly*rad) + 0.0003*math.Sin(3*meanAnomaly*rad)
+	eclipticLongitude := math.Mod(meanAnomaly+center+180+102.9372, 360)
+	transit := 2451545.0 + n + 0.0053*math.Sin(meanAnomaly*rad) - 0.0069*math.Sin(2*eclipticLongitude*rad)
+	declination := math.Asin(math.Sin(eclipticLongitude*rad) * math.Sin(23.4397*rad))
+	cosHourAngle := (math.Sin(-0.833*rad) - math.Sin(latitude*rad)*math.Sin(declination)) / (math.Cos(latitude*rad) * math.Cos(declination))
+	if cosHourAngle < -1 || cosHourAngle > 1 {
+		return time.Time{}, time.Time{}, errors.New("no sunrise or sunset on this day")
+	}
+	hourAngle := math.Acos(cosHourAngle) / rad
+	julianToTime := func(julian float64) time.Time {
+		seconds := (julian - 2440587.5) * 86400
+		return time.Unix(int64(seconds), 0).In(date.Location())
+	}
+	return julianToTime(transit - hourAngle/360), julianToTime(transit + hourAngle/360), nil
+}
@@ -0,0 +1,68 @@
+package table
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// TableConfig configures one of several tables managed by the server.
+// Leds, Seats and Corners give the layout of the strip, see NewLayout.
+// Colormap and Brightness are applied at startup, Schedule is the file the
+// schedule rules of the table are stored in.
+type TableConfig struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name,omitempty"`
+	Output     OutputConfig      `json:"output"`
+	Leds       int               `json:"leds,omitempty"`
+	Seats      map[string]string `json:"seats,omitempty"`
+	Corners    map[string]string `json:"corners,omitempty"`
+	Colormap   string            `json:"colormap,omitempty"`
+	Brightness *int              `json:"brightness,omitempty"`
+	Schedule   string            `json:"schedule,omitempty"`
+}
+
+// Layout returns the layout of the table strip.
+func (config TableConfig) Layout() (Layout, error) {
+	return NewLayout(config.Leds, config.Seats, config.Corners)
+}
+
+// LoadTableConfigs reads a JSON list of tables from path.
+func LoadTableConfigs(path string) ([]TableConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	configs := []TableConfig{}
+	err = json.Unmarshal(data, &configs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid table file %s: %v", path, err)
+	}
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("no tables in %s", path)
+	}
+	ids := map[string]bool{}
+	for i := range configs {
+		config := &configs[i]
+		if config.ID == "" {
+			return nil, fmt.Errorf("table without id in %s", path)
+		}
+		if ids[config.ID] {
+			return nil, fmt.Errorf("duplicate table id %q in %s", config.ID, path)
+		}
+		ids[config.ID] = true
+		if config.Name == "" {
+			config.Name = config.ID
+		}
+		if config.Schedule == "" {
+			config.Schedule = "schedule-" + config.ID + ".json"
+		}
+		if config.Brightness != nil && (*config.Brightness < 0 || *config.Brightness > 255) {
+			return nil, fmt.Errorf("invalid brightness for table %q", config.ID)
+		}
+		if _, err := config.Layout(); err != nil {
+			return nil, fmt.Errorf("invalid layout for table %q: %v", config.ID, err)
+		}
+	}
+	return configs, nil
+}
@@ -0,0 +1,155 @@
+package table
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SeatState describes whether a seat takes part in the turn rotation.
+type SeatState string
+
+// Available seat states.
+const (
+	SeatPlaying    SeatState = "playing"
+	SeatPassed     SeatState = "passed"
+	SeatEliminated SeatState = "eliminated"
+)
+
+const passedSeatLevel = 0.3
+const eliminatedSeatLevel = 0.1
+
+// ParseSeatState parses a seat state name.
+func ParseSeatState(encoded string) (SeatState, error) {
+	switch state := SeatState(encoded); state {
+	case SeatPlaying, SeatPassed, SeatEliminated:
+		return state, nil
+	}
+	return "", fmt.Errorf("unknown seat state %q", encoded)
+}
+
+// seatLevel returns the brightness a seat is shown with in the given state.
+func (s SeatState) seatLevel() float64 {
+	switch s {
+	case SeatPassed:
+		return passedSeatLevel
+	case SeatEliminated:
+		return eliminatedSeatLevel
+	}
+	return 1
+}
+
+// SetSeatState marks a seat as playing, passed or eliminated. Passed and
+// eliminated seats are left out of the rotation and shown dimmed.
+func (pt *AnimationPlayTable) SetSeatState(direction Direction, state SeatState) error {
+	direction, err := pt.checkDirection(direction)
+	if err != nil {
+		return err
+	}
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	if state == SeatPlaying {
+		delete(pt.seatStates, direction)
+	} else {
+		pt.seatStates[direction] = state
+	}
+	return pt.updateFrame()
+}
+
+// GetSeatState returns the state of a seat.
+func (pt *AnimationPlayTable) GetSeatState(direction Direction) SeatState {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	return pt.seatState(direction)
+}
+
+func (pt *AnimationPlayTable) seatState(direction Direction) SeatState {
+	if state, ok := pt.seatStates[direction]; ok {
+		return state
+	}
+	return SeatPlaying
+}
+
+// NewRound brings all passed seats back into the rotation. Eliminated seats
+// stay out.
+func (pt *AnimationPlayTable) NewRound() error {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	for direction, state := range pt.seatStates {
+		if state == SeatPassed {
+			delete(pt.seatStates, direction)
+		}
+	}
+	return pt.updateFrame()
+}
+
+// Reverse reverses the turn order.
+func (pt *AnimationPlayTable) Reverse() {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	pt.reversed = !pt.reversed
+}
+
+// IsReversed returns true if the turn order runs counterclockwise.
+func (pt *AnimationPlayTable) IsReversed() bool {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	return pt.reversed
+}
+
+// ActiveDirectionNext switches to the next active direction
+func (pt *AnimationPlayTable) ActiveDirectionNext() error {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	return pt.moveActiveDirection(1, pt.reversed)
+}
+
+// ActiveDirectionPrevious switches back to the previous active direction
+func (pt *AnimationPlayTable) ActiveDirectionPrevious() error {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	return pt.moveActiveDirection(1, !pt.reversed)
+}
+
+// ActiveDirectionSkipNext skips the next direction and activates the one after
+func (pt *AnimationPlayTable) ActiveDirectionSkipNext() error {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	return pt.moveActiveDirection(2, pt.reversed)
+}
+
+// moveActiveDirection moves the active direction by count seats that are in
+// the rotation, clockwise unless counterclockwise is set.
+func (pt *AnimationPlayTable) moveActiveDirection(count int, counterclockwise bool) error {
+	if pt.activeDirection == nil {
+		return errors.New("no active direction")
+	}
+	seats := pt.layout.SeatDirections()
+	index := -1
+	for i, direction := range seats {
+		if direction == *pt.activeDirection {
+			index = i
+		}
+	}
+	if index < 0 {
+		return errors.New("active direction does not match known directions")
+	}
+	step := 1
+	if counterclockwise {
+		step = len(seats) - 1
+	}
+	for moved := 0; moved < count; {
+		next := index
+		for tries := 0; ; tries++ {
+			if tries == len(seats) {
+				return errors.New("no seat left in rotation")
+			}
+			next = (next + step) % len(seats)
+			if pt.seatState(seats[next]) == SeatPlaying {
+				break
+			}
+		}
+		index = next
+		moved++
+	}
+	return pt.setActiveDirection(seats[index])
+}
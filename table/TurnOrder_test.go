@@ -0,0 +1,123 @@
+package table
+
+import (
+	"testing"
+)
+
+func TestParseSeatState(t *testing.T) {
+	tests := []struct {
+		encoded string
+		state   SeatState
+		valid   bool
+	}{
+		{"playing", SeatPlaying, true},
+		{"passed", SeatPassed, true},
+		{"eliminated", SeatEliminated, true},
+		{"Passed", "", false},
+		{"asleep", "", false},
+	}
+	for _, test := range tests {
+		state, err := ParseSeatState(test.encoded)
+		if (err == nil) != test.valid || state != test.state {
+			t.Errorf("%q: state %q, error %v, want %q", test.encoded, state, err, test.state)
+		}
+	}
+}
+
+func TestTurnOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		reversed bool
+		states   map[string]SeatState
+		move     func(*AnimationPlayTable) error
+		seat     string
+	}{
+		{"next", false, nil, (*AnimationPlayTable).ActiveDirectionNext, "bottom"},
+		{"previous", false, nil, (*AnimationPlayTable).ActiveDirectionPrevious, "top"},
+		{"skip next", false, nil, (*AnimationPlayTable).ActiveDirectionSkipNext, "left"},
+		{"reversed next", true, nil, (*AnimationPlayTable).ActiveDirectionNext, "top"},
+		{"reversed previous", true, nil, (*AnimationPlayTable).ActiveDirectionPrevious, "bottom"},
+		{"reversed skip next", true, nil, (*AnimationPlayTable).ActiveDirectionSkipNext, "left"},
+		{"next passes a passed seat", false, map[string]SeatState{"bottom": SeatPassed}, (*AnimationPlayTable).ActiveDirectionNext, "left"},
+		{"skip next passes an eliminated seat", false, map[string]SeatState{"left": SeatEliminated}, (*AnimationPlayTable).ActiveDirectionSkipNext, "top"},
+		{"last seat in the rotation", false, map[string]SeatState{"bottom": SeatPassed, "left": SeatEliminated, "top": SeatPassed}, (*AnimationPlayTable).ActiveDirectionNext, "right"},
+	}
+	for _, test := range tests {
+		_, _, playTable := newTestTable(t)
+		err := playTable.SetActiveDirection(Directions["right"])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if test.reversed {
+			playTable.Reverse()
+		}
+		for seat, state := range test.states {
+			err = playTable.SetSeatState(Directions[seat], state)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		err = test.move(playTable)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if seat := activeSeat(playTable); seat != test.seat {
+			t.Errorf("%s: active seat %q, want %q", test.name, seat, test.seat)
+		}
+	}
+}
+
+func TestTurnOrderWithoutSeats(t *testing.T) {
+	_, _, playTable := newTestTable(t)
+	if err := playTable.ActiveDirectionNext(); err == nil {
+		t.Error("expected an error without an active seat")
+	}
+	err := playTable.SetActiveDirection(Directions["right"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, seat := range SeatNames {
+		err = playTable.SetSeatState(Directions[seat], SeatEliminated)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = playTable.ActiveDirectionNext(); err == nil {
+		t.Error("expected an error with all seats eliminated")
+	}
+}
+
+func TestNewRound(t *testing.T) {
+	leds, _, playTable := newTestTable(t)
+	leds.StopAnimation()
+	frameBuffer := leds.GetFrameBuffer()
+	err := playTable.SetSeatState(Directions["bottom"], SeatPassed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = playTable.SetSeatState(Directions["left"], SeatEliminated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// blue seats dimmed by their state
+	if pixel := pixelAt(frameBuffer, Directions["bottom"].start); pixel != (Color{0, 0, 76}) {
+		t.Errorf("passed seat pixel %s, want #00004c", pixel.Hex())
+	}
+	if pixel := pixelAt(frameBuffer, Directions["left"].start); pixel != (Color{0, 0, 25}) {
+		t.Errorf("eliminated seat pixel %s, want #000019", pixel.Hex())
+	}
+	err = playTable.NewRound()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state := playTable.GetSeatState(Directions["bottom"]); state != SeatPlaying {
+		t.Errorf("passed seat is %s after a new round, want playing", state)
+	}
+	if state := playTable.GetSeatState(Directions["left"]); state != SeatEliminated {
+		t.Errorf("eliminated seat is %s after a new round", state)
+	}
+	if pixel := pixelAt(frameBuffer, Directions["bottom"].start); pixel != Colors["blue"] {
+		t.Errorf("seat pixel %s after a new round, want blue", pixel.Hex())
+	}
+}
@@ -0,0 +1,175 @@
+package table
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// StripRange maps Count LEDs starting at the logical index Start to the
+// physical index Offset of a controller. Reversed ranges run backwards on
+// the controller, for strips mounted the other way round.
+type StripRange struct {
+	Start   int  `json:"start"`
+	Count   int  `json:"count"`
+	Offset  int  `json:"offset"`
+	Reverse bool `json:"reverse,omitempty"`
+}
+
+// StripSegment is a controller of a virtual strip and the ranges it shows.
+type StripSegment struct {
+	Output OutputConfig `json:"output"`
+	Ranges []StripRange `json:"ranges"`
+}
+
+type stripPart struct {
+	output Output
+	ranges []StripRange
+	frame  []byte
+}
+
+// VirtualStrip is an output that spreads the logical LEDs of a frame over
+// several controllers, so seats can cross controller boundaries. Frames
+// are sent to all controllers at the same time.
+type VirtualStrip struct {
+	parts    []*stripPart
+	ledCount int
+}
+
+// NewVirtualStrip connects to the controllers of the segments.
+func NewVirtualStrip(segments []StripSegment) (*VirtualStrip, error) {
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("virtual strip without segments")
+	}
+	strip := new(VirtualStrip)
+	for i, segment := range segments {
+		if strings.ToLower(segment.Output.Type) == "virtual" {
+			return nil, fmt.Errorf("segment %d: virtual strips cannot be nested", i)
+		}
+		// controller frames keep the usual size unless the ranges need more
+		size := DefaultLedCount * 3
+		for _, r := range segment.Ranges {
+			if r.Count <= 0 || r.Start < 0 || r.Offset < 0 {
+				return nil, fmt.Errorf("segment %d: invalid range %d..%d", i, r.Start, r.Start+r.Count-1)
+			}
+			if (r.Offset+r.Count)*3 > size {
+				size = (r.Offset + r.Count) * 3
+			}
+			if r.Start+r.Count > strip.ledCount {
+				strip.ledCount = r.Start + r.Count
+			}
+		}
+		output, err := NewOutput(segment.Output)
+		if err != nil {
+			strip.Close()
+			return nil, fmt.Errorf("segment %d: %v", i, err)
+		}
+		strip.parts = append(strip.parts, &stripPart{output, segment.Ranges, make([]byte, size)})
+	}
+	return strip, nil
+}
+
+// String lists the controllers.
+func (vs *VirtualStrip) String() string {
+	outputs := []string{}
+	for _, part := range vs.parts {
+		outputs = append(outputs, part.output.String())
+	}
+	return "virtual(" + strings.Join(outputs, ", ") + ")"
+}
+
+// LedCount returns the number of logical LEDs, up to the end of the last range.
+func (vs *VirtualStrip) LedCount() int {
+	return vs.ledCount
+}
+
+// each runs f for all controllers in parallel and returns the first error.
+func (vs *VirtualStrip) each(f func(part *stripPart) error) error {
+	errs := make([]error, len(vs.parts))
+	var wait sync.WaitGroup
+	for i, part := range vs.parts {
+		wait.Add(1)
+		go func(i int, part *stripPart) {
+			defer wait.Done()
+			errs[i] = f(part)
+		}(i, part)
+	}
+	wait.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return fmt.Errorf("%s: %v", vs.parts[i].output, err)
+		}
+	}
+	return nil
+}
+
+// Connect connects all controllers.
+func (vs *VirtualStrip) Connect() error {
+	return vs.each(func(part *stripPart) error {
+		return part.output.Connect()
+	})
+}
+
+// Close closes all controllers.
+func (vs *VirtualStrip) Close() error {
+	return vs.each(func(part *stripPart) error {
+		return part.output.Close()
+	})
+}
+
+// StartStream prepares all controllers.
+func (vs *VirtualStrip) StartStream() error {
+	return vs.each(func(part *stripPart) error {
+		return part.output.StartStream()
+	})
+}
+
+// SendFrame maps the frame to the controllers and sends it.
+func (vs *VirtualStrip) SendFrame(frame []byte) error {
+	for _, part := range vs.parts {
+		for _, r := range part.ranges {
+			for i := 0; i < r.Count && (r.Start+i)*3 < len(frame); i++ {
+				source := (r.Start + i) * 3
+				target := (r.Offset + i) * 3
+				if r.Reverse {
+					target = (r.Offset + r.Count - 1 - i) * 3
+				}
+				copy(part.frame[target:target+3], frame[source:source+3])
+			}
+		}
+	}
+	return vs.each(func(part *stripPart) error {
+		return part.output.SendFrame(part.frame)
+	})
+}
+
+// SupportsBrightness returns true if all controllers have a brightness setting.
+func (vs *VirtualStrip) SupportsBrightness() bool {
+	for _, part := range vs.parts {
+		if !part.output.SupportsBrightness() {
+			return false
+		}
+	}
+	return true
+}
+
+// SetBrightness sets the brightness of all controllers. If one of them has
+// no brightness setting, the others are set to full brightness and the
+// whole strip is dimmed in software, so all parts match.
+func (vs *VirtualStrip) SetBrightness(value byte) error {
+	if !vs.SupportsBrightness() {
+		err := vs.each(func(part *stripPart) error {
+			if !part.output.SupportsBrightness() {
+				return nil
+			}
+			return part.output.SetBrightness(255)
+		})
+		if err != nil {
+			return err
+		}
+		return ErrBrightnessUnsupported
+	}
+	return vs.each(func(part *stripPart) error {
+		return part.output.SetBrightness(value)
+	})
+}
@@ -0,0 +1,73 @@
+package table
+
+import (
+	"testing"
+)
+
+func newTestStrip(t *testing.T) (*VirtualStrip, *FakeOutput, *FakeOutput) {
+	strip, err := NewVirtualStrip([]StripSegment{
+		{OutputConfig{Type: "fake"}, []StripRange{{Start: 0, Count: 200, Offset: 0}}},
+		{OutputConfig{Type: "fake"}, []StripRange{{Start: 200, Count: 250, Offset: 0, Reverse: true}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strip, strip.parts[0].output.(*FakeOutput), strip.parts[1].output.(*FakeOutput)
+}
+
+func TestVirtualStripFrame(t *testing.T) {
+	strip, first, second := newTestStrip(t)
+	if strip.LedCount() != 450 {
+		t.Fatalf("led count %d, want 450", strip.LedCount())
+	}
+	leds := NewLeds(strip, DefaultLedCount)
+	frame := *leds.GetFrameBuffer()
+	if len(frame) != 450*3 {
+		t.Fatalf("frame of %d bytes, want %d", len(frame), 450*3)
+	}
+	frame[0] = 1
+	frame[200*3] = 2
+	frame[449*3] = 3
+	err := strip.SendFrame(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent, _ := first.GetFrame()
+	if sent[0] != 1 {
+		t.Errorf("first controller pixel 0 is %d, want 1", sent[0])
+	}
+	sent, _ = second.GetFrame()
+	// controller frames keep the usual size
+	if len(sent) != DefaultLedCount*3 {
+		t.Errorf("second controller frame of %d bytes, want %d", len(sent), DefaultLedCount*3)
+	}
+	if sent[0] != 3 || sent[249*3] != 2 {
+		t.Errorf("reversed range not mapped: pixel 0 is %d, pixel 249 is %d", sent[0], sent[249*3])
+	}
+}
+
+func TestVirtualStripBrightness(t *testing.T) {
+	strip, first, second := newTestStrip(t)
+	if !strip.SupportsBrightness() {
+		t.Fatal("strip of fake controllers without brightness setting")
+	}
+	err := strip.SetBrightness(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.GetBrightness() != 100 || second.GetBrightness() != 100 {
+		t.Errorf("brightness %d and %d, want 100", first.GetBrightness(), second.GetBrightness())
+	}
+	second.SetBrightnessSupported(false)
+	if strip.SupportsBrightness() {
+		t.Error("strip with a controller without brightness setting supports brightness")
+	}
+	err = strip.SetBrightness(50)
+	if err != ErrBrightnessUnsupported {
+		t.Fatalf("error %v, want %v", err, ErrBrightnessUnsupported)
+	}
+	// the strip is dimmed in software, the other controller shows full brightness
+	if first.GetBrightness() != 255 {
+		t.Errorf("brightness %d, want 255", first.GetBrightness())
+	}
+}
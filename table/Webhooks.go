@@ -0,0 +1,134 @@
+package table
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+const webhookAttempts = 4
+const webhookRetryDelay = time.Second
+const webhookTimeout = 10 * time.Second
+
+// Webhook is an outgoing HTTP endpoint notified about events. If Events is
+// empty, all events are sent. With a secret, the body is signed with
+// HMAC-SHA256 in the X-Boardgametable-Signature header.
+type Webhook struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret,omitempty"`
+	Events []string `json:"events,omitempty"`
+}
+
+// WebhookDispatcher posts events to webhooks, retrying failed deliveries
+// with a doubling delay.
+type WebhookDispatcher struct {
+	webhooks   []Webhook
+	client     *http.Client
+	retryDelay time.Duration
+}
+
+// LoadWebhooks reads a JSON list of webhooks from path.
+func LoadWebhooks(path string) ([]Webhook, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	webhooks := []Webhook{}
+	err = json.Unmarshal(data, &webhooks)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook file %s: %v", path, err)
+	}
+	for _, webhook := range webhooks {
+		if webhook.URL == "" {
+			return nil, fmt.Errorf("webhook without url in %s", path)
+		}
+	}
+	return webhooks, nil
+}
+
+// NewWebhookDispatcher creates a dispatcher for the given webhooks.
+func NewWebhookDispatcher(webhooks []Webhook) *WebhookDispatcher {
+	dispatcher := new(WebhookDispatcher)
+	dispatcher.webhooks = webhooks
+	dispatcher.client = &http.Client{Timeout: webhookTimeout}
+	dispatcher.retryDelay = webhookRetryDelay
+	return dispatcher
+}
+
+// Dispatch sends the event to all interested webhooks in the background.
+// It can be used as an EventListener.
+func (wd *WebhookDispatcher) Dispatch(event Event) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		fmt.Println("error marshalling webhook event:", err)
+		return
+	}
+	for _, webhook := range wd.webhooks {
+		if !webhook.wants(event.Type) {
+			continue
+		}
+		go wd.deliver(webhook, event.Type, payload)
+	}
+}
+
+func (w Webhook) wants(eventType string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign returns the signature of payload for the given secret.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (wd *WebhookDispatcher) deliver(webhook Webhook, eventType string, payload []byte) {
+	delay := wd.retryDelay
+	for attempt := 1; attempt <= webhookAttempts; attempt++ {
+		err := wd.post(webhook, eventType, payload)
+		if err == nil {
+			return
+		}
+		fmt.Printf("webhook %s attempt %d failed: %v\n", webhook.URL, attempt, err)
+		if attempt < webhookAttempts {
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+}
+
+func (wd *WebhookDispatcher) post(webhook Webhook, eventType string, payload []byte) error {
+	request, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Boardgametable-Event", eventType)
+	if webhook.Secret != "" {
+		request.Header.Set("X-Boardgametable-Signature", Sign(webhook.Secret, payload))
+	}
+	response, err := wd.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	ioutil.ReadAll(response.Body)
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", response.Status)
+	}
+	return nil
+}
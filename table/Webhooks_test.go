@@ -0,0 +1,120 @@
+package table
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	signature := Sign("secret", []byte(`{"type":"turn"}`))
+	if signature != "sha256=70fc2318bff3197555f78d240503d6d8a0d1fef227a9d6460b15cec97d2f5dab" {
+		t.Errorf("signature %s", signature)
+	}
+	if Sign("other", []byte(`{"type":"turn"}`)) == signature {
+		t.Error("same signature for another secret")
+	}
+}
+
+func TestWebhookWants(t *testing.T) {
+	tests := []struct {
+		events []string
+		event  string
+		wants  bool
+	}{
+		{nil, "turn", true},
+		{[]string{"turn"}, "turn", true},
+		{[]string{"phase", "turn"}, "turn", true},
+		{[]string{"phase"}, "turn", false},
+	}
+	for _, test := range tests {
+		if wants := (Webhook{Events: test.events}).wants(test.event); wants != test.wants {
+			t.Errorf("%v wants %s: %v, want %v", test.events, test.event, wants, test.wants)
+		}
+	}
+}
+
+// webhookServer answers the first failures requests with an error and
+// records the signatures of all requests.
+type webhookServer struct {
+	failures   int
+	lock       sync.Mutex
+	signatures []string
+	events     []string
+	bodies     []string
+}
+
+func (ws *webhookServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	ws.lock.Lock()
+	defer ws.lock.Unlock()
+	ws.signatures = append(ws.signatures, r.Header.Get("X-Boardgametable-Signature"))
+	ws.events = append(ws.events, r.Header.Get("X-Boardgametable-Event"))
+	ws.bodies = append(ws.bodies, string(body))
+	if len(ws.signatures) <= ws.failures {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (ws *webhookServer) requests() int {
+	ws.lock.Lock()
+	defer ws.lock.Unlock()
+	return len(ws.signatures)
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		secret   string
+		requests int
+	}{
+		{"delivered", 0, "", 1},
+		{"signed", 0, "secret", 1},
+		{"retried", 2, "secret", 3},
+		{"given up", 10, "", webhookAttempts},
+	}
+	payload := []byte(`{"event":"turn"}`)
+	for _, test := range tests {
+		server := &webhookServer{failures: test.failures}
+		httpServer := httptest.NewServer(server)
+		dispatcher := NewWebhookDispatcher(nil)
+		dispatcher.retryDelay = time.Millisecond
+		webhook := Webhook{URL: httpServer.URL, Secret: test.secret}
+		dispatcher.deliver(webhook, "turn", payload)
+		httpServer.Close()
+		if server.requests() != test.requests {
+			t.Errorf("%s: %d requests, want %d", test.name, server.requests(), test.requests)
+			continue
+		}
+		for i, signature := range server.signatures {
+			want := ""
+			if test.secret != "" {
+				want = Sign(test.secret, payload)
+			}
+			if signature != want || server.events[i] != "turn" || server.bodies[i] != string(payload) {
+				t.Errorf("%s: request %d with signature %q, event %q, body %q", test.name, i+1, signature, server.events[i], server.bodies[i])
+			}
+		}
+	}
+}
+
+func TestWebhookDispatch(t *testing.T) {
+	server := &webhookServer{}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	dispatcher := NewWebhookDispatcher([]Webhook{
+		{URL: httpServer.URL, Events: []string{"turn"}},
+		{URL: httpServer.URL, Events: []string{"phase"}},
+		{URL: httpServer.URL},
+	})
+	dispatcher.Dispatch(Event{Type: "turn", Table: "kitchen"})
+	waitFor(t, "webhook requests", func() bool { return server.requests() == 2 })
+	time.Sleep(20 * time.Millisecond)
+	if server.requests() != 2 {
+		t.Errorf("%d requests, want 2 for the interested webhooks", server.requests())
+	}
+}
@@ -0,0 +1,88 @@
+package table
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// WledDefaultPort is the UDP realtime port of WLED.
+const WledDefaultPort = 21324
+
+const wledProtocolDnrgb = 4
+const wledTimeoutSeconds = 2
+const wledMaxLeds = 489
+
+// Wled sends frames to a WLED controller using the UDP realtime protocol
+// (DNRGB) and sets the brightness with the JSON API. WLED returns to its
+// own effects two seconds after the last frame.
+type Wled struct {
+	udpOutput
+	client *http.Client
+}
+
+// NewWled connects to a WLED controller.
+func NewWled(host string, port int) (*Wled, error) {
+	if port == 0 {
+		port = WledDefaultPort
+	}
+	wled := new(Wled)
+	wled.host = host
+	wled.port = port
+	wled.client = &http.Client{Timeout: 5 * time.Second}
+	err := wled.Connect()
+	if err != nil {
+		return nil, err
+	}
+	return wled, nil
+}
+
+// String returns the address of the controller.
+func (w *Wled) String() string {
+	return "wled " + w.address()
+}
+
+// StartStream switches the controller on.
+func (w *Wled) StartStream() error {
+	return w.postState(`{"on":true}`)
+}
+
+// SendFrame sends the frame, split into packets of at most 489 LEDs.
+func (w *Wled) SendFrame(frame []byte) error {
+	for start := 0; start < len(frame)/3; start += wledMaxLeds {
+		end := start + wledMaxLeds
+		if end > len(frame)/3 {
+			end = len(frame) / 3
+		}
+		packet := []byte{wledProtocolDnrgb, wledTimeoutSeconds, byte(start >> 8), byte(start)}
+		packet = append(packet, frame[start*3:end*3]...)
+		err := w.send(packet)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SupportsBrightness returns true, WLED has a master brightness.
+func (w *Wled) SupportsBrightness() bool {
+	return true
+}
+
+// SetBrightness sets the master brightness of the controller.
+func (w *Wled) SetBrightness(value byte) error {
+	return w.postState(fmt.Sprintf(`{"bri":%d}`, value))
+}
+
+func (w *Wled) postState(state string) error {
+	response, err := w.client.Post("http://"+w.host+"/json/state", "application/json", bytes.NewBufferString(state))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("wled responded %s", response.Status)
+	}
+	return nil
+}